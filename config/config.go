@@ -23,12 +23,59 @@ type LoggingConfig struct {
 	File  string `yaml:"file"`
 }
 
+// 数据比对模式
+const (
+	CompareModeFull     = "full"     // 逐行读取并比对全部数据
+	CompareModeChecksum = "checksum" // 按键范围分块计算校验值，仅对校验值不同的块逐行比对
+)
+
+// CompareConfig 表示差异比对的配置
+type CompareConfig struct {
+	Mode              string              `yaml:"mode"`                // 数据比对模式：full, checksum
+	ChunkSize         int                 `yaml:"chunk_size"`          // 数据比对时每批读取的行数
+	ChecksumChunkSize int                 `yaml:"checksum_chunk_size"` // checksum 模式下初始的分块行数
+	ChecksumTargetMs  int                 `yaml:"checksum_target_ms"`  // checksum 模式下每块校验查询的目标耗时（毫秒），分块大小据此自动调整
+	FloatEpsilon      float64             `yaml:"float_epsilon"`       // FLOAT/DOUBLE 列比对时允许的绝对误差，0 表示按数值精确比较
+	CharsetDrift      bool                `yaml:"charset_drift"`       // 比对表和列的字符集和排序规则，默认忽略
+	Partitions        map[string][]string `yaml:"partitions"`          // 数据比对时只读取指定的分区，key 为表名，未配置的表比对全部数据
+}
+
+// GenerateConfig 表示 SQL 生成的配置
+type GenerateConfig struct {
+	CreateEventsDisabled bool `yaml:"create_events_disabled"` // 新建的定时事件一律为 DISABLE 状态，修改事件时保留目标库中的状态
+	ReorderColumns       bool `yaml:"reorder_columns"`        // 调整目标库中顺序不同的列，可能导致重建表
+	DropPartitions       bool `yaml:"drop_partitions"`        // 删除目标库独有的分区（会同时删除分区中的数据），关闭时只报告
+}
+
+// RenameConfig 表示显式声明的改名，声明的改名无需确认即生成改名语句
+type RenameConfig struct {
+	Tables  map[string]string            `yaml:"tables"`  // 旧表名 → 新表名
+	Columns map[string]map[string]string `yaml:"columns"` // 表名 → 旧列名 → 新列名
+}
+
+// 目标库独有的表（孤立表）的处理策略
+const (
+	OrphanPolicyIgnore = "ignore" // 不比对也不报告
+	OrphanPolicyReport = "report" // 在差异汇总中报告，不生成 SQL
+	OrphanPolicyDrop   = "drop"   // 生成 DROP TABLE（开启 archive 时改名归档而不删除）
+)
+
+// OrphanTableConfig 表示目标库独有的表的处理配置
+type OrphanTableConfig struct {
+	Policy  string `yaml:"policy"`  // 处理策略：ignore, report, drop
+	Archive bool   `yaml:"archive"` // drop 策略下改名为 <表名>_archived_<时间戳> 而不是删除
+}
+
 // Config 表示完整的应用配置
 type Config struct {
-	Source         DatabaseConfig `yaml:"source"`
-	Target         DatabaseConfig `yaml:"target"`
-	SyncDataTables []string       `yaml:"sync_data_tables"`
-	Logging        LoggingConfig  `yaml:"logging"`
+	Source         DatabaseConfig    `yaml:"source"`
+	Target         DatabaseConfig    `yaml:"target"`
+	SyncDataTables []string          `yaml:"sync_data_tables"`
+	Compare        CompareConfig     `yaml:"compare"`
+	Generate       GenerateConfig    `yaml:"generate"`
+	Renames        RenameConfig      `yaml:"renames"`
+	OrphanTables   OrphanTableConfig `yaml:"orphan_tables"`
+	Logging        LoggingConfig     `yaml:"logging"`
 }
 
 // LoadConfig 从 YAML 文件加载配置
@@ -76,5 +123,29 @@ func (c *Config) Validate() error {
 	if c.Target.Charset == "" {
 		c.Target.Charset = "utf8mb4"
 	}
+	if c.Compare.Mode == "" {
+		c.Compare.Mode = CompareModeFull
+	}
+	if c.Compare.Mode != CompareModeFull && c.Compare.Mode != CompareModeChecksum {
+		return fmt.Errorf("invalid compare mode %q, must be %q or %q", c.Compare.Mode, CompareModeFull, CompareModeChecksum)
+	}
+	if c.OrphanTables.Policy == "" {
+		c.OrphanTables.Policy = OrphanPolicyReport
+	}
+	switch c.OrphanTables.Policy {
+	case OrphanPolicyIgnore, OrphanPolicyReport, OrphanPolicyDrop:
+	default:
+		return fmt.Errorf("invalid orphan table policy %q, must be %q, %q or %q",
+			c.OrphanTables.Policy, OrphanPolicyIgnore, OrphanPolicyReport, OrphanPolicyDrop)
+	}
+	if c.Compare.ChunkSize <= 0 {
+		c.Compare.ChunkSize = 1000
+	}
+	if c.Compare.ChecksumChunkSize <= 0 {
+		c.Compare.ChecksumChunkSize = 10000
+	}
+	if c.Compare.ChecksumTargetMs <= 0 {
+		c.Compare.ChecksumTargetMs = 500
+	}
 	return nil
 }
@@ -82,4 +82,37 @@ func TestValidateConfig(t *testing.T) {
 	if cfg.Source.Charset != "utf8mb4" {
 		t.Errorf("Expected default charset utf8mb4, got %s", cfg.Source.Charset)
 	}
+	if cfg.Compare.ChunkSize != 1000 {
+		t.Errorf("Expected default chunk size 1000, got %d", cfg.Compare.ChunkSize)
+	}
+	if cfg.Compare.Mode != CompareModeFull {
+		t.Errorf("Expected default compare mode %s, got %s", CompareModeFull, cfg.Compare.Mode)
+	}
+	if cfg.OrphanTables.Policy != OrphanPolicyReport {
+		t.Errorf("Expected default orphan table policy %s, got %s", OrphanPolicyReport, cfg.OrphanTables.Policy)
+	}
+}
+
+func TestValidateConfigInvalidCompareMode(t *testing.T) {
+	cfg := &Config{
+		Source:  DatabaseConfig{Host: "localhost", Database: "source_db"},
+		Target:  DatabaseConfig{Host: "localhost", Database: "target_db"},
+		Compare: CompareConfig{Mode: "fast"},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid compare mode, got nil")
+	}
+}
+
+func TestValidateConfigInvalidOrphanPolicy(t *testing.T) {
+	cfg := &Config{
+		Source:       DatabaseConfig{Host: "localhost", Database: "source_db"},
+		Target:       DatabaseConfig{Host: "localhost", Database: "target_db"},
+		OrphanTables: OrphanTableConfig{Policy: "delete"},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid orphan table policy, got nil")
+	}
 }
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestKeyBoundaryQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		lower     models.RowKey
+		chunkSize int
+		query     string
+		args      []interface{}
+	}{
+		{
+			name:      "first chunk",
+			chunkSize: 1000,
+			query:     "SELECT `id` FROM `orders` ORDER BY `id` LIMIT 1 OFFSET 999",
+		},
+		{
+			name:      "following chunk",
+			lower:     models.RowKey{int64(1000)},
+			chunkSize: 500,
+			query:     "SELECT `id` FROM `orders` WHERE `id` > ? ORDER BY `id` LIMIT 1 OFFSET 499",
+			args:      []interface{}{int64(1000)},
+		},
+		{
+			name:      "chunk of one row",
+			chunkSize: 0,
+			query:     "SELECT `id` FROM `orders` ORDER BY `id` LIMIT 1 OFFSET 0",
+		},
+	}
+
+	for _, tt := range tests {
+		query, args := keyBoundaryQuery("`orders`", []string{"id"}, tt.lower, tt.chunkSize)
+		if query != tt.query {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.query, query)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: expected args %v, got %v", tt.name, tt.args, args)
+		}
+	}
+}
+
+func TestChunkChecksumQuery(t *testing.T) {
+	const selectExpr = "SELECT COUNT(*), COALESCE(BIT_XOR(CRC32(CONCAT_WS('#', `id`, `name`, CONCAT(ISNULL(`id`), ISNULL(`name`))))), 0) FROM `users`"
+	tests := []struct {
+		name         string
+		lower, upper models.RowKey
+		query        string
+		args         []interface{}
+	}{
+		{
+			name:  "whole table",
+			query: selectExpr,
+		},
+		{
+			name:  "first chunk",
+			upper: models.RowKey{int64(100)},
+			query: selectExpr + " WHERE `id` <= ?",
+			args:  []interface{}{int64(100)},
+		},
+		{
+			name:  "middle chunk",
+			lower: models.RowKey{int64(100)},
+			upper: models.RowKey{int64(200)},
+			query: selectExpr + " WHERE `id` > ? AND `id` <= ?",
+			args:  []interface{}{int64(100), int64(200)},
+		},
+		{
+			name:  "last chunk",
+			lower: models.RowKey{int64(200)},
+			query: selectExpr + " WHERE `id` > ?",
+			args:  []interface{}{int64(200)},
+		},
+	}
+
+	for _, tt := range tests {
+		query, args := chunkChecksumQuery("`users`", []string{"id", "name"}, []string{"id"}, tt.lower, tt.upper)
+		if query != tt.query {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.query, query)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: expected args %v, got %v", tt.name, tt.args, args)
+		}
+	}
+}
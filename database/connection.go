@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"sync"
@@ -12,8 +13,10 @@ import (
 
 // Connection 表示数据库连接
 type Connection struct {
-	db   *sql.DB
-	name string // 连接名，用于日志
+	db       *sql.DB
+	name     string        // 连接名，用于日志
+	database string        // 连接的库名
+	version  ServerVersion // 连接时检测的服务端类型和版本
 }
 
 // NewConnection 创建新的数据库连接
@@ -42,12 +45,31 @@ func NewConnection(cfg *config.DatabaseConfig, name string) (*Connection, error)
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	// 检测服务端类型和版本，用于兼容不同版本返回的元数据
+	var rawVersion string
+	if err := db.QueryRow("SELECT VERSION()").Scan(&rawVersion); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to query server version: %w", err)
+	}
+
 	return &Connection{
-		db:   db,
-		name: name,
+		db:       db,
+		name:     name,
+		database: cfg.Database,
+		version:  ParseServerVersion(rawVersion),
 	}, nil
 }
 
+// Database 返回连接的库名
+func (c *Connection) Database() string {
+	return c.database
+}
+
+// Version 返回服务端的类型和版本
+func (c *Connection) Version() ServerVersion {
+	return c.version
+}
+
 // GetDB 获取底层数据库连接
 func (c *Connection) GetDB() *sql.DB {
 	return c.db
@@ -73,6 +95,27 @@ func (c *Connection) Exec(query string, args ...interface{}) (sql.Result, error)
 	return c.db.Exec(query, args...)
 }
 
+// ExecWithTimeZone 在指定的会话时区下执行 SQL 语句，执行后恢复连接原来的时区
+func (c *Connection) ExecWithTimeZone(timeZone, query string, args ...interface{}) (sql.Result, error) {
+	ctx := context.Background()
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var saved string
+	if err := conn.QueryRowContext(ctx, "SELECT @@session.time_zone").Scan(&saved); err != nil {
+		return nil, fmt.Errorf("failed to read session time zone: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "SET time_zone = ?", timeZone); err != nil {
+		return nil, fmt.Errorf("failed to set session time zone %s: %w", timeZone, err)
+	}
+	defer conn.ExecContext(ctx, "SET time_zone = ?", saved)
+
+	return conn.ExecContext(ctx, query, args...)
+}
+
 // BeginTx 开启事务
 func (c *Connection) BeginTx() (*sql.Tx, error) {
 	return c.db.Begin()
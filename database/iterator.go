@@ -0,0 +1,185 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// RowIterator 按键列顺序分批遍历表中的行
+// 使用 keyset 分页（WHERE 键列 > 上一批最后的键 ORDER BY 键列 LIMIT n，复合键按列展开比较），
+// 每次只在内存中保留一批数据，遍历整张表只需一次顺序扫描
+type RowIterator struct {
+	qh         *QueryHelper
+	tableName  string
+	keyColumns []string
+	chunkSize  int
+	lowerBound models.RowKey // 不包含该键，nil 表示从头开始
+	upperBound models.RowKey // 包含该键，nil 表示直到表尾
+
+	chunk     []map[string]interface{}
+	pos       int
+	lastKey   models.RowKey
+	exhausted bool
+	err       error
+}
+
+// NewRowIterator 创建按键列遍历表数据的迭代器
+func (qh *QueryHelper) NewRowIterator(tableName string, keyColumns []string, chunkSize int) *RowIterator {
+	if chunkSize <= 0 {
+		chunkSize = 1000
+	}
+	return &RowIterator{
+		qh:         qh,
+		tableName:  tableName,
+		keyColumns: keyColumns,
+		chunkSize:  chunkSize,
+		pos:        -1,
+	}
+}
+
+// WithRange 限定遍历的键范围为 (lower, upper]，nil 表示该方向不设限
+func (it *RowIterator) WithRange(lower, upper models.RowKey) *RowIterator {
+	it.lowerBound = lower
+	it.upperBound = upper
+	it.lastKey = lower
+	return it
+}
+
+// Next 移动到下一行，没有更多数据或出错时返回 false
+func (it *RowIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.pos++
+	if it.pos < len(it.chunk) {
+		return true
+	}
+
+	if it.exhausted {
+		return false
+	}
+
+	if err := it.fetch(); err != nil {
+		it.err = err
+		return false
+	}
+
+	it.pos = 0
+	return len(it.chunk) > 0
+}
+
+// Row 返回当前行
+func (it *RowIterator) Row() map[string]interface{} {
+	return it.chunk[it.pos]
+}
+
+// Key 返回当前行的键值
+func (it *RowIterator) Key() models.RowKey {
+	return models.RowKeyOf(it.chunk[it.pos], it.keyColumns)
+}
+
+// Err 返回遍历过程中发生的错误
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// fetch 读取下一批数据
+func (it *RowIterator) fetch() error {
+	where, args := keyRangeCondition(it.keyColumns, it.lastKey, it.upperBound)
+
+	query := fmt.Sprintf("SELECT * FROM %s%s ORDER BY %s LIMIT %d", it.qh.tableRef(it.tableName), where, quoteColumns(it.keyColumns), it.chunkSize)
+
+	rows, err := it.qh.conn.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query rows of table %s: %w", it.tableName, err)
+	}
+	defer rows.Close()
+
+	chunk, err := scanRows(rows)
+	if err != nil {
+		return err
+	}
+
+	it.chunk = chunk
+	if len(chunk) < it.chunkSize {
+		it.exhausted = true
+	}
+	if len(chunk) > 0 {
+		it.lastKey = models.RowKeyOf(chunk[len(chunk)-1], it.keyColumns)
+	}
+
+	return nil
+}
+
+// keyRangeCondition 生成键范围 (lower, upper] 的 WHERE 子句（含前导空格）及参数
+// lower 或 upper 为 nil 时该方向不设限，两者都为 nil 时返回空字符串
+// 复合键展开为 k1 > ? OR (k1 = ? AND k2 > ?) 的形式：MySQL 对行构造器比较 (k1, k2) > (?, ?) 不使用索引范围扫描
+func keyRangeCondition(keyColumns []string, lower, upper models.RowKey) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if lower != nil {
+		condition, conditionArgs := keysetCondition(keyColumns, keysetArgs(lower), ">", ">")
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	if upper != nil {
+		condition, conditionArgs := keysetCondition(keyColumns, keysetArgs(upper), "<", "<=")
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// keysetCondition 按字典序比较键列与键值：前面的列相等时比较下一列，
+// 前缀列使用严格比较 operator，最后一列使用 lastOperator（用于 <= 这样包含边界的比较）
+func keysetCondition(keyColumns []string, key []interface{}, operator, lastOperator string) (string, []interface{}) {
+	var branches []string
+	var args []interface{}
+
+	for i := range keyColumns {
+		op := operator
+		if i == len(keyColumns)-1 {
+			op = lastOperator
+		}
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("`%s` = ?", keyColumns[j]))
+			args = append(args, key[j])
+		}
+		parts = append(parts, fmt.Sprintf("`%s` %s ?", keyColumns[i], op))
+		args = append(args, key[i])
+
+		branch := strings.Join(parts, " AND ")
+		if len(keyColumns) > 1 && i > 0 {
+			branch = "(" + branch + ")"
+		}
+		branches = append(branches, branch)
+	}
+
+	if len(branches) == 1 {
+		return branches[0], args
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+// keysetArgs 将键值转换为查询参数
+// 文本协议返回的 []byte 需要转为 string，否则会以二进制字符串比较，与列的排序规则不一致
+func keysetArgs(key models.RowKey) []interface{} {
+	args := make([]interface{}, len(key))
+	for i, val := range key {
+		if b, ok := val.([]byte); ok {
+			args[i] = string(b)
+		} else {
+			args[i] = val
+		}
+	}
+	return args
+}
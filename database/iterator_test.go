@@ -0,0 +1,58 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestKeyRangeCondition(t *testing.T) {
+	tests := []struct {
+		name         string
+		keyColumns   []string
+		lower, upper models.RowKey
+		where        string
+		args         []interface{}
+	}{
+		{
+			name:       "unbounded",
+			keyColumns: []string{"id"},
+			where:      "",
+		},
+		{
+			name:       "one column",
+			keyColumns: []string{"id"},
+			lower:      models.RowKey{int64(10)},
+			upper:      models.RowKey{int64(20)},
+			where:      " WHERE `id` > ? AND `id` <= ?",
+			args:       []interface{}{int64(10), int64(20)},
+		},
+		{
+			name:       "two columns",
+			keyColumns: []string{"user_id", "role_id"},
+			lower:      models.RowKey{int64(1), []byte("admin")},
+			where:      " WHERE (`user_id` > ? OR (`user_id` = ? AND `role_id` > ?))",
+			args:       []interface{}{int64(1), int64(1), "admin"},
+		},
+		{
+			name:       "three columns",
+			keyColumns: []string{"a", "b", "c"},
+			lower:      models.RowKey{1, 2, 3},
+			upper:      models.RowKey{4, 5, 6},
+			where: " WHERE (`a` > ? OR (`a` = ? AND `b` > ?) OR (`a` = ? AND `b` = ? AND `c` > ?))" +
+				" AND (`a` < ? OR (`a` = ? AND `b` < ?) OR (`a` = ? AND `b` = ? AND `c` <= ?))",
+			args: []interface{}{1, 1, 2, 1, 2, 3, 4, 4, 5, 4, 5, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		where, args := keyRangeCondition(tt.keyColumns, tt.lower, tt.upper)
+		if where != tt.where {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.where, where)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: expected args %v, got %v", tt.name, tt.args, args)
+		}
+	}
+}
@@ -3,18 +3,63 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/yuhuo/sync-db/models"
 )
 
 // QueryHelper 辅助进行数据库查询
 type QueryHelper struct {
-	conn *Connection
+	conn              *Connection
+	infoSchemaColumns map[string]bool     // 缓存 INFORMATION_SCHEMA 表中是否存在某列，key: 表名.列名
+	partitions        map[string][]string // 读取数据时限定的分区，key: 表名
 }
 
 // NewQueryHelper 创建查询助手
 func NewQueryHelper(conn *Connection) *QueryHelper {
-	return &QueryHelper{conn: conn}
+	return &QueryHelper{
+		conn:              conn,
+		infoSchemaColumns: make(map[string]bool),
+	}
+}
+
+// WithPartitions 设置读取数据时限定的分区（表名 → 分区名），未设置的表读取全部数据
+func (qh *QueryHelper) WithPartitions(partitions map[string][]string) *QueryHelper {
+	qh.partitions = partitions
+	return qh
+}
+
+// tableRef 返回读取数据时引用表的写法，限定了分区的表附加 PARTITION 子句
+func (qh *QueryHelper) tableRef(tableName string) string {
+	ref := "`" + tableName + "`"
+	if partitions := qh.partitions[tableName]; len(partitions) > 0 {
+		ref += " PARTITION (" + quoteColumns(partitions) + ")"
+	}
+	return ref
+}
+
+// hasInfoSchemaColumn 检查 INFORMATION_SCHEMA 中的表是否包含某列
+// 用于兼容不同版本的 MySQL/MariaDB（例如 STATISTICS.IS_VISIBLE 仅 MySQL 8.0 才有）
+func (qh *QueryHelper) hasInfoSchemaColumn(tableName, columnName string) (bool, error) {
+	cacheKey := tableName + "." + columnName
+	if exists, cached := qh.infoSchemaColumns[cacheKey]; cached {
+		return exists, nil
+	}
+
+	var count int
+	err := qh.conn.QueryRow(`
+		SELECT COUNT(*)
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = 'information_schema' AND TABLE_NAME = ? AND COLUMN_NAME = ?
+	`, tableName, columnName).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check information_schema column %s: %w", cacheKey, err)
+	}
+
+	qh.infoSchemaColumns[cacheKey] = count > 0
+	return count > 0, nil
 }
 
 // GetTables 获取数据库中的所有表列表（不包括视图）
@@ -49,12 +94,11 @@ func (qh *QueryHelper) GetTableDefinition(tableName string) (*models.TableDefini
 	}
 
 	// 获取列定义
-	columns, primaryKey, err := qh.getColumns(tableName)
+	columns, err := qh.getColumns(tableName)
 	if err != nil {
 		return nil, err
 	}
 	tableDef.Columns = columns
-	tableDef.PrimaryKey = primaryKey
 
 	// 获取索引定义
 	indexes, err := qh.getIndexes(tableName)
@@ -63,55 +107,272 @@ func (qh *QueryHelper) GetTableDefinition(tableName string) (*models.TableDefini
 	}
 	tableDef.Indexes = indexes
 
+	// 获取外键定义
+	foreignKeys, err := qh.getForeignKeys(tableName)
+	if err != nil {
+		return nil, err
+	}
+	tableDef.ForeignKeys = foreignKeys
+
+	// 获取 CHECK 约束定义
+	checks, err := qh.getCheckConstraints(tableName)
+	if err != nil {
+		return nil, err
+	}
+	tableDef.CheckConstraints = checks
+
+	// MariaDB 的 JSON 是 LONGTEXT 加 json_valid 约束的别名，还原为 JSON 列以便与 MySQL 比对
+	if qh.conn.Version().IsMariaDB() {
+		restoreMariaDBJSONColumns(tableDef)
+	}
+
+	// 获取表选项
+	if err := qh.getTableOptions(tableDef); err != nil {
+		return nil, err
+	}
+
+	// 获取分区定义
+	partitioning, err := qh.getPartitioning(tableName)
+	if err != nil {
+		return nil, err
+	}
+	tableDef.Partitioning = partitioning
+
+	// 主键列取自 PRIMARY 索引，保证复合主键的列顺序与 SEQ_IN_INDEX 一致
+	for _, idx := range indexes {
+		if idx.Type == "PRIMARY" {
+			tableDef.PrimaryKey = idx.Columns
+			break
+		}
+	}
+
 	return tableDef, nil
 }
 
-// getColumns 获取表的列定义
-func (qh *QueryHelper) getColumns(tableName string) ([]models.Column, string, error) {
+// getTableOptions 读取表的存储引擎、行格式、字符集、注释、压缩和自增值等选项
+// INFORMATION_SCHEMA.TABLES 中的 AUTO_INCREMENT 在 MySQL 8.0 中有缓存（information_schema_stats_expiry），
+// 因此自增值取自 SHOW CREATE TABLE
+func (qh *QueryHelper) getTableOptions(tableDef *models.TableDefinition) error {
+	var engine, rowFormat, collation, createOptions sql.NullString
+	var comment string
+	err := qh.conn.QueryRow(`
+		SELECT ENGINE, ROW_FORMAT, TABLE_COLLATION, TABLE_COMMENT, CREATE_OPTIONS
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+	`, tableDef.TableName).Scan(&engine, &rowFormat, &collation, &comment, &createOptions)
+	if err != nil {
+		return fmt.Errorf("failed to query table options: %w", err)
+	}
+
+	tableDef.Engine = engine.String
+	tableDef.RowFormat = rowFormat.String
+	tableDef.Comment = comment
+	if collation.Valid {
+		charset := models.CharsetOfCollation(collation.String)
+		tableDef.Collation = &collation.String
+		tableDef.Charset = &charset
+	}
+
+	createSQL, err := qh.GetCreateTableSQL(tableDef.TableName)
+	if err != nil {
+		return err
+	}
+	tableDef.AutoIncrement = parseAutoIncrement(createSQL)
+
+	// CREATE_OPTIONS 形如：row_format=COMPRESSED KEY_BLOCK_SIZE=8 COMPRESSION="zlib"
+	for _, option := range strings.Fields(createOptions.String) {
+		name, value, found := strings.Cut(option, "=")
+		if !found {
+			continue
+		}
+		switch strings.ToUpper(name) {
+		case "KEY_BLOCK_SIZE":
+			fmt.Sscanf(value, "%d", &tableDef.KeyBlockSize)
+		case "COMPRESSION":
+			if value = strings.Trim(value, `"'`); !strings.EqualFold(value, "none") {
+				tableDef.Compression = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// autoIncrementPattern 匹配 SHOW CREATE TABLE 末行表选项中的 AUTO_INCREMENT，它位于所有带引号的表选项之前
+var autoIncrementPattern = regexp.MustCompile(`(?m)^\)[^']*?\bAUTO_INCREMENT=(\d+)`)
+
+// parseAutoIncrement 从 CREATE TABLE 语句中解析下一个自增值，没有自增列或计数器未初始化时返回 0
+func parseAutoIncrement(createSQL string) uint64 {
+	match := autoIncrementPattern.FindStringSubmatch(createSQL)
+	if match == nil {
+		return 0
+	}
+	value, _ := strconv.ParseUint(match[1], 10, 64)
+	return value
+}
+
+// getPartitioning 获取表的分区定义，未分区的表返回 nil
+// 子分区的表每个子分区占一行，这里只取每个分区的第一行，不记录子分区
+func (qh *QueryHelper) getPartitioning(tableName string) (*models.Partitioning, error) {
 	rows, err := qh.conn.Query(`
+		SELECT PARTITION_NAME, PARTITION_METHOD, PARTITION_EXPRESSION, PARTITION_DESCRIPTION
+		FROM INFORMATION_SCHEMA.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL
+		ORDER BY PARTITION_ORDINAL_POSITION, SUBPARTITION_ORDINAL_POSITION
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitioning *models.Partitioning
+	for rows.Next() {
+		var name, method string
+		var expression, description sql.NullString
+		if err := rows.Scan(&name, &method, &expression, &description); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+
+		if partitioning == nil {
+			partitioning = &models.Partitioning{Method: method, Expression: expression.String}
+		}
+		if n := len(partitioning.Partitions); n > 0 && partitioning.Partitions[n-1].Name == name {
+			continue
+		}
+		partitioning.Partitions = append(partitioning.Partitions, models.Partition{
+			Name:        name,
+			Description: description.String,
+		})
+	}
+
+	return partitioning, rows.Err()
+}
+
+// onUpdatePattern 匹配 EXTRA 中的 ON UPDATE 子句
+var onUpdatePattern = regexp.MustCompile(`(?i)on update (\S+)`)
+
+// parseQuotedDefault 解析 MariaDB 10.2.7+ 以 SQL 写法返回的 COLUMN_DEFAULT
+// 带引号的是字符串字面量，NULL 表示默认值为 NULL，数字为字面量，其余为表达式（如 current_timestamp()）
+func parseQuotedDefault(value string) (*string, bool) {
+	switch {
+	case value == "NULL":
+		return nil, false
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		literal := strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		return &literal, false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return &value, false
+	}
+	return &value, true
+}
+
+// restoreMariaDBJSONColumns 将带有 json_valid(`列名`) 约束的 LONGTEXT 列还原为 JSON 列，并去掉该约束
+func restoreMariaDBJSONColumns(tableDef *models.TableDefinition) {
+	var checks []models.CheckConstraint
+	for _, check := range tableDef.CheckConstraints {
+		col := tableDef.GetColumnByName(check.Name)
+		if col != nil && strings.EqualFold(col.Type, "longtext") &&
+			strings.EqualFold(strings.Join(strings.Fields(check.Expression), ""), "json_valid(`"+col.Name+"`)") {
+			col.Type = "json"
+			col.Charset = nil
+			col.Collation = nil
+			continue
+		}
+		checks = append(checks, check)
+	}
+	tableDef.CheckConstraints = checks
+}
+
+// isTemporalType 判断列类型是否为 TIMESTAMP 或 DATETIME
+func isTemporalType(columnType string) bool {
+	lower := strings.ToLower(columnType)
+	return strings.HasPrefix(lower, "timestamp") || strings.HasPrefix(lower, "datetime")
+}
+
+// getColumns 获取表的列定义
+func (qh *QueryHelper) getColumns(tableName string) ([]models.Column, error) {
+	// GENERATION_EXPRESSION 仅 MySQL 5.7+ / MariaDB 10.2+ 才有
+	hasGeneration, err := qh.hasInfoSchemaColumn("COLUMNS", "GENERATION_EXPRESSION")
+	if err != nil {
+		return nil, err
+	}
+	generationExpr := "''"
+	if hasGeneration {
+		generationExpr = "IFNULL(GENERATION_EXPRESSION, '')"
+	}
+
+	rows, err := qh.conn.Query(fmt.Sprintf(`
 		SELECT
-			COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE,
-			COLUMN_DEFAULT, EXTRA, COLUMN_KEY,
-			CHARACTER_SET_NAME, COLLATION_NAME, COLUMN_COMMENT
+			COLUMN_NAME, ORDINAL_POSITION, COLUMN_TYPE, IS_NULLABLE,
+			COLUMN_DEFAULT, EXTRA,
+			CHARACTER_SET_NAME, COLLATION_NAME, COLUMN_COMMENT, %s
 		FROM INFORMATION_SCHEMA.COLUMNS
 		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
 		ORDER BY ORDINAL_POSITION
-	`, tableName)
+	`, generationExpr), tableName)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to query columns: %w", err)
+		return nil, fmt.Errorf("failed to query columns: %w", err)
 	}
 	defer rows.Close()
 
 	var columns []models.Column
-	var primaryKey string
 
 	for rows.Next() {
 		var (
 			name             string
+			position         int
 			columnType       string
 			isNullable       string
 			defaultValue     sql.NullString
 			extra            string
-			columnKey        string
 			characterSetName sql.NullString
 			collationName    sql.NullString
 			columnComment    sql.NullString
+			generation       string
 		)
 
-		if err := rows.Scan(&name, &columnType, &isNullable, &defaultValue, &extra, &columnKey, &characterSetName, &collationName, &columnComment); err != nil {
-			return nil, "", fmt.Errorf("failed to scan column: %w", err)
+		if err := rows.Scan(&name, &position, &columnType, &isNullable, &defaultValue, &extra, &characterSetName, &collationName, &columnComment, &generation); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
 		}
 
 		col := models.Column{
 			Name:            name,
+			Position:        position,
 			Type:            columnType,
 			IsNullable:      isNullable == "YES",
 			Extra:           extra,
 			IsAutoIncrement: extra == "auto_increment",
 		}
 
-		if defaultValue.Valid {
-			col.DefaultValue = &defaultValue.String
+		// EXTRA 为 VIRTUAL GENERATED 或 STORED GENERATED（MariaDB 也可能为 PERSISTENT GENERATED）
+		upperExtra := strings.ToUpper(extra)
+		if generation != "" && strings.Contains(upperExtra, "GENERATED") && !strings.Contains(upperExtra, "DEFAULT_GENERATED") {
+			col.GenerationExpression = generation
+			col.GeneratedStorage = "VIRTUAL"
+			if strings.Contains(upperExtra, "STORED") || strings.Contains(upperExtra, "PERSISTENT") {
+				col.GeneratedStorage = "STORED"
+			}
+		}
+
+		// 默认值：MySQL 8.0 的 EXTRA 以 DEFAULT_GENERATED 标记表达式默认值，其中的引号被转义为 \'；
+		// 5.7 没有该标记，TIMESTAMP/DATETIME 列的 CURRENT_TIMESTAMP 默认值同样是表达式
+		if defaultValue.Valid && !col.IsGenerated() && qh.conn.Version().QuotesColumnDefaults() {
+			col.DefaultValue, col.DefaultIsExpression = parseQuotedDefault(defaultValue.String)
+		} else if defaultValue.Valid && !col.IsGenerated() {
+			value := defaultValue.String
+			if strings.Contains(upperExtra, "DEFAULT_GENERATED") {
+				value = strings.ReplaceAll(value, `\'`, "'")
+				col.DefaultIsExpression = true
+			} else if isTemporalType(columnType) && models.IsCurrentTimestamp(value) {
+				col.DefaultIsExpression = true
+			}
+			col.DefaultValue = &value
+		}
+
+		// EXTRA 中的 ON UPDATE 子句，如 on update CURRENT_TIMESTAMP(3)
+		if match := onUpdatePattern.FindStringSubmatch(extra); match != nil {
+			col.OnUpdate = match[1]
 		}
 
 		if characterSetName.Valid {
@@ -127,23 +388,41 @@ func (qh *QueryHelper) getColumns(tableName string) ([]models.Column, string, er
 		}
 
 		columns = append(columns, col)
-
-		if columnKey == "PRI" {
-			primaryKey = name
-		}
 	}
 
-	return columns, primaryKey, rows.Err()
+	return columns, rows.Err()
 }
 
 // getIndexes 获取表的索引定义
 func (qh *QueryHelper) getIndexes(tableName string) ([]models.Index, error) {
-	rows, err := qh.conn.Query(`
-		SELECT INDEX_NAME, COLUMN_NAME, SEQ_IN_INDEX
+	// 可见性和函数索引表达式仅在较新版本中提供，不存在时使用默认值
+	visibleExpr := "'YES'"
+	if ok, err := qh.hasInfoSchemaColumn("STATISTICS", "IS_VISIBLE"); err != nil {
+		return nil, err
+	} else if ok {
+		visibleExpr = "IS_VISIBLE"
+	} else if ok, err := qh.hasInfoSchemaColumn("STATISTICS", "IGNORED"); err != nil {
+		return nil, err
+	} else if ok {
+		visibleExpr = "IF(IGNORED = 'YES', 'NO', 'YES')"
+	}
+
+	expressionExpr := "NULL"
+	if ok, err := qh.hasInfoSchemaColumn("STATISTICS", "EXPRESSION"); err != nil {
+		return nil, err
+	} else if ok {
+		expressionExpr = "EXPRESSION"
+	}
+
+	rows, err := qh.conn.Query(fmt.Sprintf(`
+		SELECT
+			INDEX_NAME, COLUMN_NAME, SEQ_IN_INDEX, NON_UNIQUE,
+			INDEX_TYPE, SUB_PART, COLLATION, INDEX_COMMENT,
+			%s AS IS_VISIBLE, %s AS EXPRESSION
 		FROM INFORMATION_SCHEMA.STATISTICS
 		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
 		ORDER BY INDEX_NAME, SEQ_IN_INDEX
-	`, tableName)
+	`, visibleExpr, expressionExpr), tableName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query indexes: %w", err)
 	}
@@ -153,27 +432,61 @@ func (qh *QueryHelper) getIndexes(tableName string) ([]models.Index, error) {
 	indexOrder := []string{}
 
 	for rows.Next() {
-		var indexName, columnName string
-		var seqInIndex int
+		var (
+			indexName    string
+			columnName   sql.NullString
+			seqInIndex   int
+			nonUnique    int
+			indexType    string
+			subPart      sql.NullInt64
+			collation    sql.NullString
+			indexComment string
+			isVisible    string
+			expression   sql.NullString
+		)
 
-		if err := rows.Scan(&indexName, &columnName, &seqInIndex); err != nil {
+		if err := rows.Scan(&indexName, &columnName, &seqInIndex, &nonUnique,
+			&indexType, &subPart, &collation, &indexComment, &isVisible, &expression); err != nil {
 			return nil, fmt.Errorf("failed to scan index: %w", err)
 		}
 
 		if _, exists := indexMap[indexName]; !exists {
-			indexType := "INDEX"
-			if indexName == "PRIMARY" {
-				indexType = "PRIMARY"
+			kind := "INDEX"
+			method := indexType
+			switch {
+			case indexName == "PRIMARY":
+				kind = "PRIMARY"
+			case indexType == "FULLTEXT" || indexType == "SPATIAL":
+				kind = indexType
+				method = ""
+			case nonUnique == 0:
+				kind = "UNIQUE"
 			}
 			indexMap[indexName] = &models.Index{
 				Name:    indexName,
-				Type:    indexType,
+				Type:    kind,
 				Columns: []string{},
+				Method:  method,
+				Visible: isVisible != "NO",
+				Comment: indexComment,
 			}
 			indexOrder = append(indexOrder, indexName)
 		}
 
-		indexMap[indexName].Columns = append(indexMap[indexName].Columns, columnName)
+		part := models.IndexPart{
+			Column:     columnName.String,
+			SubPart:    int(subPart.Int64),
+			Descending: collation.String == "D",
+		}
+		if expression.Valid {
+			part.Expression = expression.String
+		}
+
+		idx := indexMap[indexName]
+		idx.Parts = append(idx.Parts, part)
+		if columnName.Valid {
+			idx.Columns = append(idx.Columns, columnName.String)
+		}
 	}
 
 	var indexes []models.Index
@@ -184,6 +497,138 @@ func (qh *QueryHelper) getIndexes(tableName string) ([]models.Index, error) {
 	return indexes, rows.Err()
 }
 
+// getForeignKeys 获取表的外键定义
+func (qh *QueryHelper) getForeignKeys(tableName string) ([]models.ForeignKey, error) {
+	rows, err := qh.conn.Query(`
+		SELECT
+			rc.CONSTRAINT_NAME, rc.UPDATE_RULE, rc.DELETE_RULE,
+			IF(kcu.REFERENCED_TABLE_SCHEMA = DATABASE(), '', kcu.REFERENCED_TABLE_SCHEMA),
+			kcu.REFERENCED_TABLE_NAME, kcu.COLUMN_NAME, kcu.REFERENCED_COLUMN_NAME
+		FROM INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+		JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+			ON kcu.CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+			AND kcu.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+			AND kcu.TABLE_NAME = rc.TABLE_NAME
+		WHERE rc.CONSTRAINT_SCHEMA = DATABASE() AND rc.TABLE_NAME = ?
+		ORDER BY rc.CONSTRAINT_NAME, kcu.ORDINAL_POSITION
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	fkMap := make(map[string]*models.ForeignKey)
+	fkOrder := []string{}
+
+	for rows.Next() {
+		var name, updateRule, deleteRule, refSchema, refTable, columnName, refColumnName string
+		if err := rows.Scan(&name, &updateRule, &deleteRule, &refSchema, &refTable, &columnName, &refColumnName); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+
+		if _, exists := fkMap[name]; !exists {
+			fkMap[name] = &models.ForeignKey{
+				Name:             name,
+				ReferencedSchema: refSchema,
+				ReferencedTable:  refTable,
+				OnDelete:         deleteRule,
+				OnUpdate:         updateRule,
+			}
+			fkOrder = append(fkOrder, name)
+		}
+
+		fk := fkMap[name]
+		fk.Columns = append(fk.Columns, columnName)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumnName)
+	}
+
+	var foreignKeys []models.ForeignKey
+	for _, name := range fkOrder {
+		foreignKeys = append(foreignKeys, *fkMap[name])
+	}
+
+	return foreignKeys, rows.Err()
+}
+
+// getCheckConstraints 获取表的 CHECK 约束定义
+// MySQL 8.0.16 之前没有 CHECK_CONSTRAINTS 表，返回空列表；MariaDB 没有 ENFORCED 列，约束总是强制执行
+func (qh *QueryHelper) getCheckConstraints(tableName string) ([]models.CheckConstraint, error) {
+	hasChecks, err := qh.hasInfoSchemaColumn("CHECK_CONSTRAINTS", "CHECK_CLAUSE")
+	if err != nil || !hasChecks {
+		return nil, err
+	}
+	hasEnforced, err := qh.hasInfoSchemaColumn("TABLE_CONSTRAINTS", "ENFORCED")
+	if err != nil {
+		return nil, err
+	}
+	// MariaDB 的约束名仅在表内唯一，需要同时按表名关联
+	hasTableName, err := qh.hasInfoSchemaColumn("CHECK_CONSTRAINTS", "TABLE_NAME")
+	if err != nil {
+		return nil, err
+	}
+
+	enforcedExpr := "'YES'"
+	if hasEnforced {
+		enforcedExpr = "tc.ENFORCED"
+	}
+	tableJoin := ""
+	if hasTableName {
+		tableJoin = "AND cc.TABLE_NAME = tc.TABLE_NAME"
+	}
+
+	rows, err := qh.conn.Query(fmt.Sprintf(`
+		SELECT tc.CONSTRAINT_NAME, cc.CHECK_CLAUSE, %s
+		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS cc
+			ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+			%s
+		WHERE tc.TABLE_SCHEMA = DATABASE() AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE = 'CHECK'
+		ORDER BY tc.CONSTRAINT_NAME
+	`, enforcedExpr, tableJoin), tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var checks []models.CheckConstraint
+	for rows.Next() {
+		var check models.CheckConstraint
+		var enforced string
+		if err := rows.Scan(&check.Name, &check.Expression, &enforced); err != nil {
+			return nil, fmt.Errorf("failed to scan check constraint: %w", err)
+		}
+		check.Enforced = enforced == "YES"
+		checks = append(checks, check)
+	}
+
+	return checks, rows.Err()
+}
+
+// FindCheckViolations 查找不满足 CHECK 表达式的行，返回违反的行数和最多 limit 行样本
+// 表达式结果为 NULL 的行满足约束，因此只统计 NOT (expr) 为真的行
+func (qh *QueryHelper) FindCheckViolations(tableName, expression string, limit int) (int64, []map[string]interface{}, error) {
+	var count int64
+	if err := qh.conn.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM `%s` WHERE NOT (%s)", tableName, expression)).Scan(&count); err != nil {
+		return 0, nil, fmt.Errorf("failed to check constraint violations: %w", err)
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	rows, err := qh.conn.Query(fmt.Sprintf("SELECT * FROM `%s` WHERE NOT (%s) LIMIT %d", tableName, expression, limit))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to query constraint violations: %w", err)
+	}
+	defer rows.Close()
+
+	samples, err := scanRows(rows)
+	if err != nil {
+		return 0, nil, err
+	}
+	return count, samples, nil
+}
+
 // GetViews 获取数据库中的所有视图
 func (qh *QueryHelper) GetViews() ([]models.ViewDefinition, error) {
 	rows, err := qh.conn.Query(`
@@ -210,7 +655,7 @@ func (qh *QueryHelper) GetViews() ([]models.ViewDefinition, error) {
 			ViewName: viewName,
 		}
 		if viewDef.Valid {
-			view.Definition = viewDef.String
+			view.Definition = stripSchemaQualifier(viewDef.String, qh.conn.Database())
 		}
 
 		views = append(views, view)
@@ -219,75 +664,228 @@ func (qh *QueryHelper) GetViews() ([]models.ViewDefinition, error) {
 	return views, rows.Err()
 }
 
-// GetPrimaryKeyValues 获取表的主键值列表
-func (qh *QueryHelper) GetPrimaryKeyValues(tableName, primaryKeyColumn string) (map[interface{}]bool, error) {
-	if primaryKeyColumn == "" {
-		return nil, fmt.Errorf("table %s has no primary key", tableName)
+// GetTriggers 获取数据库中的所有触发器
+func (qh *QueryHelper) GetTriggers() ([]models.TriggerDefinition, error) {
+	rows, err := qh.conn.Query(`
+		SELECT
+			TRIGGER_NAME, EVENT_OBJECT_TABLE, ACTION_TIMING,
+			EVENT_MANIPULATION, ACTION_ORDER, ACTION_STATEMENT
+		FROM INFORMATION_SCHEMA.TRIGGERS
+		WHERE TRIGGER_SCHEMA = DATABASE()
+		ORDER BY EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION, ACTION_ORDER
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []models.TriggerDefinition
+	for rows.Next() {
+		var trigger models.TriggerDefinition
+		if err := rows.Scan(&trigger.TriggerName, &trigger.TableName, &trigger.Timing,
+			&trigger.Event, &trigger.ActionOrder, &trigger.Body); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		trigger.Body = stripSchemaQualifier(trigger.Body, qh.conn.Database())
+		triggers = append(triggers, trigger)
 	}
 
-	query := fmt.Sprintf("SELECT `%s` FROM `%s`", primaryKeyColumn, tableName)
-	rows, err := qh.conn.Query(query)
+	return triggers, rows.Err()
+}
+
+// GetRoutines 获取数据库中的所有存储过程和函数
+// 完整定义（参数、返回值、特性、SQL SECURITY 和语句体）来自 SHOW CREATE PROCEDURE/FUNCTION
+func (qh *QueryHelper) GetRoutines() ([]models.RoutineDefinition, error) {
+	rows, err := qh.conn.Query(`
+		SELECT ROUTINE_NAME, ROUTINE_TYPE, SECURITY_TYPE
+		FROM INFORMATION_SCHEMA.ROUTINES
+		WHERE ROUTINE_SCHEMA = DATABASE()
+		ORDER BY ROUTINE_TYPE, ROUTINE_NAME
+	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query primary key values: %w", err)
+		return nil, fmt.Errorf("failed to query routines: %w", err)
 	}
-	defer rows.Close()
 
-	pkValues := make(map[interface{}]bool)
+	var routines []models.RoutineDefinition
 	for rows.Next() {
-		var pkValue interface{}
-		if err := rows.Scan(&pkValue); err != nil {
-			return nil, fmt.Errorf("failed to scan pk value: %w", err)
+		var routine models.RoutineDefinition
+		if err := rows.Scan(&routine.RoutineName, &routine.RoutineType, &routine.SecurityType); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan routine: %w", err)
 		}
-		pkValues[pkValue] = true
+		routines = append(routines, routine)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query routines: %w", err)
 	}
 
-	return pkValues, rows.Err()
+	for i := range routines {
+		createSQL, err := qh.getCreateRoutineSQL(routines[i].RoutineType, routines[i].RoutineName)
+		if err != nil {
+			return nil, err
+		}
+		if !createSQL.Valid {
+			// 没有权限查看定义时 CREATE 语句为 NULL，由比对时跳过
+			routines[i].Hidden = true
+			continue
+		}
+		routines[i].CreateSQL = stripSchemaQualifier(stripDefiner(createSQL.String), qh.conn.Database())
+	}
+
+	return routines, nil
 }
 
-// GetRowByPrimaryKey 根据主键获取一行数据
-func (qh *QueryHelper) GetRowByPrimaryKey(tableName, primaryKeyColumn string, pkValue interface{}) (map[string]interface{}, error) {
-	query := fmt.Sprintf("SELECT * FROM `%s` WHERE `%s` = ?", tableName, primaryKeyColumn)
-	rows, err := qh.conn.Query(query, pkValue)
+// getCreateRoutineSQL 获取存储过程或函数的 CREATE 语句，没有权限查看定义时为 NULL
+func (qh *QueryHelper) getCreateRoutineSQL(routineType, routineName string) (sql.NullString, error) {
+	var createSQL sql.NullString
+	rows, err := qh.conn.Query(fmt.Sprintf("SHOW CREATE %s `%s`", routineType, routineName))
 	if err != nil {
-		return nil, fmt.Errorf("failed to query row: %w", err)
+		return createSQL, fmt.Errorf("failed to query create %s statement: %w", strings.ToLower(routineType), err)
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
-		return nil, nil
+		return createSQL, fmt.Errorf("no result from SHOW CREATE %s for %s", routineType, routineName)
 	}
 
-	cols, err := rows.Columns()
-	if err != nil {
-		return nil, err
+	// 结果列：名称, sql_mode, CREATE 语句, character_set_client, collation_connection, Database Collation
+	var name, sqlMode, charset, collation, dbCollation string
+	if err := rows.Scan(&name, &sqlMode, &createSQL, &charset, &collation, &dbCollation); err != nil {
+		return createSQL, fmt.Errorf("failed to scan create %s statement: %w", strings.ToLower(routineType), err)
+	}
+
+	return createSQL, nil
+}
+
+// definerPattern 匹配 CREATE 语句中的 DEFINER 子句
+var definerPattern = regexp.MustCompile("(?i)^(\\s*CREATE\\s+)DEFINER\\s*=\\s*(?:`[^`]*`|'[^']*'|[^\\s@]+)(?:@(?:`[^`]*`|'[^']*'|\\S+))?\\s+")
+
+// stripDefiner 去除 CREATE 语句中的 DEFINER 子句
+// 源库的定义者账号在目标库中不一定存在，去除后由执行同步的账号作为定义者
+func stripDefiner(createSQL string) string {
+	return definerPattern.ReplaceAllString(createSQL, "${1}")
+}
+
+// stripSchemaQualifier 去掉定义中对当前库的库名限定，如 `test_db`.`users` → `users`
+// VIEW_DEFINITION 总是以当前库名限定表名，去掉后在目标库中创建的对象引用目标库自己的表，
+// 两边的定义也不会因库名不同而被判定为修改；对其他库的引用保持不变
+// 只处理反引号形式的库名，字符串和注释中的内容原样保留，不带反引号的 app.id 可能是表别名，也不处理
+func stripSchemaQualifier(def, schema string) string {
+	if schema == "" {
+		return def
+	}
+	qualifier := "`" + strings.ReplaceAll(schema, "`", "``") + "`."
+
+	var sb strings.Builder
+	for i := 0; i < len(def); {
+		end := i + 1
+		switch c := def[i]; {
+		case c == '\'' || c == '"':
+			end = quotedEnd(def, i)
+		case c == '#' || isDashComment(def[i:]):
+			if n := strings.IndexByte(def[i:], '\n'); n >= 0 {
+				end = i + n
+			} else {
+				end = len(def)
+			}
+		case c == '/' && strings.HasPrefix(def[i:], "/*"):
+			if n := strings.Index(def[i+2:], "*/"); n >= 0 {
+				end = i + 2 + n + 2
+			} else {
+				end = len(def)
+			}
+		case c == '`':
+			// 前面是 . 时为 db.tbl.col 中的表名或列名，不是库名
+			if strings.HasPrefix(def[i:], qualifier) && (i == 0 || def[i-1] != '.') {
+				i += len(qualifier)
+				continue
+			}
+			end = quotedEnd(def, i)
+		}
+		sb.WriteString(def[i:end])
+		i = end
 	}
+	return sb.String()
+}
+
+// isDashComment 判断是否为 -- 注释（-- 之后须为空白或结尾）
+func isDashComment(s string) bool {
+	return strings.HasPrefix(s, "--") && (len(s) == 2 || strings.ContainsRune(" \t\r\n", rune(s[2])))
+}
 
-	values := make([]interface{}, len(cols))
-	valuePtrs := make([]interface{}, len(cols))
-	for i := range cols {
-		valuePtrs[i] = &values[i]
+// quotedEnd 返回从 start 开始的引号（' " `）内容结束后的位置，支持重复引号和反斜杠转义
+func quotedEnd(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote != '`':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
 	}
+	return len(s)
+}
 
-	if err := rows.Scan(valuePtrs...); err != nil {
-		return nil, fmt.Errorf("failed to scan row: %w", err)
+// GetEvents 获取数据库中的所有定时事件
+func (qh *QueryHelper) GetEvents() ([]models.EventDefinition, error) {
+	rows, err := qh.conn.Query(`
+		SELECT
+			EVENT_NAME, EVENT_TYPE, EXECUTE_AT, INTERVAL_VALUE, INTERVAL_FIELD,
+			STARTS, ENDS, STATUS, ON_COMPLETION, EVENT_COMMENT, EVENT_DEFINITION, TIME_ZONE
+		FROM INFORMATION_SCHEMA.EVENTS
+		WHERE EVENT_SCHEMA = DATABASE()
+		ORDER BY EVENT_NAME
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
 	}
+	defer rows.Close()
 
-	row := make(map[string]interface{})
-	for i, col := range cols {
-		row[col] = values[i]
+	var events []models.EventDefinition
+	for rows.Next() {
+		var event models.EventDefinition
+		var executeAt, starts, ends sql.NullTime
+		var intervalValue, intervalField sql.NullString
+		if err := rows.Scan(&event.EventName, &event.EventType, &executeAt, &intervalValue, &intervalField,
+			&starts, &ends, &event.Status, &event.OnCompletion, &event.Comment, &event.Body, &event.TimeZone); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.IntervalValue = intervalValue.String
+		event.IntervalField = intervalField.String
+		if executeAt.Valid {
+			event.ExecuteAt = &executeAt.Time
+		}
+		if starts.Valid {
+			event.Starts = &starts.Time
+		}
+		if ends.Valid {
+			event.Ends = &ends.Time
+		}
+		events = append(events, event)
 	}
 
-	return row, nil
+	return events, rows.Err()
 }
 
 // GetAllRows 获取表的所有行数据
 func (qh *QueryHelper) GetAllRows(tableName string) ([]map[string]interface{}, error) {
-	rows, err := qh.conn.Query(fmt.Sprintf("SELECT * FROM `%s`", tableName))
+	rows, err := qh.conn.Query("SELECT * FROM " + qh.tableRef(tableName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query rows: %w", err)
 	}
 	defer rows.Close()
 
+	return scanRows(rows)
+}
+
+// scanRows 将查询结果逐行读取为 列名 → 值 的映射
+func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 	cols, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -335,3 +933,8 @@ func (qh *QueryHelper) GetCreateTableSQL(tableName string) (string, error) {
 
 	return createSQL, nil
 }
+
+// quoteColumns 将列名列表转换为以逗号分隔的带反引号列表
+func quoteColumns(columns []string) string {
+	return "`" + strings.Join(columns, "`, `") + "`"
+}
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestStripSchemaQualifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      string
+		expected string
+	}{
+		{
+			"view",
+			"select `app`.`users`.`id` AS `id` from `app`.`users` join `other`.`roles` on `app`.`users`.`role_id` = `other`.`roles`.`id`",
+			"select `users`.`id` AS `id` from `users` join `other`.`roles` on `users`.`role_id` = `other`.`roles`.`id`",
+		},
+		{
+			"view with alias named like the schema",
+			"select app.id from users app",
+			"select app.id from users app",
+		},
+		{
+			"trigger",
+			"BEGIN INSERT INTO `app`.`audit_log` (`msg`) VALUES ('copied to `app`.`audit_log`'); END",
+			"BEGIN INSERT INTO `audit_log` (`msg`) VALUES ('copied to `app`.`audit_log`'); END",
+		},
+		{
+			"routine with comments",
+			"CREATE PROCEDURE `p_cleanup`()\nBEGIN\n  -- purge `app`.`logs`\n  # see `app`.`logs`\n  /* `app`.`logs` */\n  DELETE FROM `app`.`logs`;\nEND",
+			"CREATE PROCEDURE `p_cleanup`()\nBEGIN\n  -- purge `app`.`logs`\n  # see `app`.`logs`\n  /* `app`.`logs` */\n  DELETE FROM `logs`;\nEND",
+		},
+		{
+			"escaped quotes in string literals",
+			"SELECT 'it''s `app`.`t`', \"a \\\" `app`.`t`\", `app`.`t`.`c` FROM `app`.`t`",
+			"SELECT 'it''s `app`.`t`', \"a \\\" `app`.`t`\", `t`.`c` FROM `t`",
+		},
+		{
+			"identifier that only starts with the schema name",
+			"SELECT * FROM `app_archive`.`t` JOIN `x`.`app`.`t`",
+			"SELECT * FROM `app_archive`.`t` JOIN `x`.`app`.`t`",
+		},
+		{
+			"subtraction is not a comment",
+			"SELECT 1--1 FROM `app`.`t`",
+			"SELECT 1--1 FROM `t`",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := stripSchemaQualifier(tt.def, "app"); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	if got := stripSchemaQualifier("SELECT * FROM `app`.`t`", ""); got != "SELECT * FROM `app`.`t`" {
+		t.Errorf("Expected definition unchanged without schema, got %q", got)
+	}
+}
+
+func TestParseAutoIncrement(t *testing.T) {
+	tests := []struct {
+		name      string
+		createSQL string
+		expected  uint64
+	}{
+		{
+			"table options",
+			"CREATE TABLE `orders` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB AUTO_INCREMENT=1042 DEFAULT CHARSET=utf8mb4",
+			1042,
+		},
+		{
+			"counter not initialized",
+			"CREATE TABLE `orders` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='AUTO_INCREMENT=5'",
+			0,
+		},
+		{
+			"column comment is not a table option",
+			"CREATE TABLE `orders` (\n  `id` int NOT NULL COMMENT 'AUTO_INCREMENT=7'\n) ENGINE=InnoDB",
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := parseAutoIncrement(tt.createSQL); got != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestParseQuotedDefault(t *testing.T) {
+	tests := []struct {
+		value        string
+		expected     *string
+		isExpression bool
+	}{
+		{"'active'", strPtr("active"), false},
+		{"'it''s'", strPtr("it's"), false},
+		{"''", strPtr(""), false},
+		{"'NULL'", strPtr("NULL"), false},
+		{"NULL", nil, false},
+		{"0", strPtr("0"), false},
+		{"-1.5", strPtr("-1.5"), false},
+		{"current_timestamp()", strPtr("current_timestamp()"), true},
+		{"curdate()", strPtr("curdate()"), true},
+	}
+
+	for _, tt := range tests {
+		got, isExpression := parseQuotedDefault(tt.value)
+		if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) || isExpression != tt.isExpression {
+			t.Errorf("%s: expected (%v, %v), got (%v, %v)", tt.value, deref(tt.expected), tt.isExpression, deref(got), isExpression)
+		}
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
+
+func TestRestoreMariaDBJSONColumns(t *testing.T) {
+	tableDef := &models.TableDefinition{
+		TableName: "settings",
+		Columns: []models.Column{
+			{Name: "payload", Type: "longtext", Charset: strPtr("utf8mb4"), Collation: strPtr("utf8mb4_bin")},
+			{Name: "raw", Type: "longtext", Charset: strPtr("utf8mb4"), Collation: strPtr("utf8mb4_general_ci")},
+			{Name: "meta", Type: "text"},
+		},
+		CheckConstraints: []models.CheckConstraint{
+			{Name: "payload", Expression: "json_valid( `payload` )", Enforced: true},
+			{Name: "raw", Expression: "octet_length(`raw`) < 1024", Enforced: true},
+			{Name: "meta", Expression: "json_valid(`meta`)", Enforced: true},
+			{Name: "chk_other", Expression: "json_valid(`raw`)", Enforced: true},
+		},
+	}
+
+	restoreMariaDBJSONColumns(tableDef)
+
+	// 只有名称与列相同、表达式为 json_valid(列) 的 LONGTEXT 列还原为 JSON
+	tests := []struct {
+		column   string
+		expected string
+		charset  bool
+	}{
+		{"payload", "json", false},
+		{"raw", "longtext", true},
+		{"meta", "text", false},
+	}
+	for _, tt := range tests {
+		col := tableDef.GetColumnByName(tt.column)
+		if col.Type != tt.expected || (col.Charset != nil) != tt.charset || (col.Collation != nil) != tt.charset {
+			t.Errorf("%s: expected type %s with charset %v, got %+v", tt.column, tt.expected, tt.charset, col)
+		}
+	}
+
+	var names []string
+	for _, check := range tableDef.CheckConstraints {
+		names = append(names, check.Name)
+	}
+	if strings.Join(names, ",") != "raw,meta,chk_other" {
+		t.Errorf("Expected only the payload constraint to be removed, got %v", names)
+	}
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 数据库服务端类型
+const (
+	FlavorMySQL   = "MySQL"
+	FlavorMariaDB = "MariaDB"
+)
+
+// ServerVersion 表示数据库服务端的类型和版本
+type ServerVersion struct {
+	Flavor string // FlavorMySQL, FlavorMariaDB
+	Major  int
+	Minor  int
+	Patch  int
+	Raw    string // SELECT VERSION() 的原始结果，如 8.0.36、10.6.16-MariaDB-log
+}
+
+// ParseServerVersion 解析 SELECT VERSION() 的结果
+// 旧版复制协议下 MariaDB 的版本带有 5.5.5- 前缀，如 5.5.5-10.6.16-MariaDB
+func ParseServerVersion(raw string) ServerVersion {
+	v := ServerVersion{Flavor: FlavorMySQL, Raw: raw}
+	version := raw
+	if strings.Contains(strings.ToLower(raw), "mariadb") {
+		v.Flavor = FlavorMariaDB
+		version = strings.TrimPrefix(version, "5.5.5-")
+	}
+	fmt.Sscanf(version, "%d.%d.%d", &v.Major, &v.Minor, &v.Patch)
+	return v
+}
+
+// AtLeast 判断版本是否不低于 major.minor.patch
+func (v ServerVersion) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// IsMariaDB 判断是否为 MariaDB
+func (v ServerVersion) IsMariaDB() bool {
+	return v.Flavor == FlavorMariaDB
+}
+
+// SupportsDescendingIndexes 判断是否支持降序索引（MySQL 8.0 / MariaDB 10.8 之前 DESC 只被解析而不生效）
+func (v ServerVersion) SupportsDescendingIndexes() bool {
+	if v.IsMariaDB() {
+		return v.AtLeast(10, 8, 0)
+	}
+	return v.AtLeast(8, 0, 0)
+}
+
+// SupportsInvisibleIndexes 判断是否支持不可见索引（MySQL 8.0 的 INVISIBLE / MariaDB 10.6 的 IGNORED）
+func (v ServerVersion) SupportsInvisibleIndexes() bool {
+	if v.IsMariaDB() {
+		return v.AtLeast(10, 6, 0)
+	}
+	return v.AtLeast(8, 0, 0)
+}
+
+// SupportsRenameColumn 判断是否支持 ALTER TABLE ... RENAME COLUMN（MySQL 8.0 / MariaDB 10.5.2）
+func (v ServerVersion) SupportsRenameColumn() bool {
+	if v.IsMariaDB() {
+		return v.AtLeast(10, 5, 2)
+	}
+	return v.AtLeast(8, 0, 0)
+}
+
+// SupportsRenameIndex 判断是否支持 ALTER TABLE ... RENAME INDEX（MySQL 5.7 / MariaDB 10.5.2）
+func (v ServerVersion) SupportsRenameIndex() bool {
+	if v.IsMariaDB() {
+		return v.AtLeast(10, 5, 2)
+	}
+	return v.AtLeast(5, 7, 0)
+}
+
+// QuotesColumnDefaults 判断 COLUMN_DEFAULT 是否以 SQL 写法返回（MariaDB 10.2.7+：字符串带引号、NULL 为 'NULL'、表达式不带引号）
+func (v ServerVersion) QuotesColumnDefaults() bool {
+	return v.IsMariaDB() && v.AtLeast(10, 2, 7)
+}
+
+// String 返回版本的可读形式，如 "MySQL 8.0.36"
+func (v ServerVersion) String() string {
+	return fmt.Sprintf("%s %d.%d.%d", v.Flavor, v.Major, v.Minor, v.Patch)
+}
@@ -3,7 +3,8 @@ module github.com/yuhuo/sync-db
 go 1.25.5
 
 require (
-	filippo.io/edwards25519 v1.1.0 // indirect
-	github.com/go-sql-driver/mysql v1.9.3 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
+	github.com/go-sql-driver/mysql v1.9.3
+	gopkg.in/yaml.v2 v2.4.0
 )
+
+require filippo.io/edwards25519 v1.1.0 // indirect
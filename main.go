@@ -8,12 +8,14 @@ import (
 	"github.com/yuhuo/sync-db/config"
 	"github.com/yuhuo/sync-db/database"
 	"github.com/yuhuo/sync-db/logger"
+	"github.com/yuhuo/sync-db/models"
 	"github.com/yuhuo/sync-db/sync"
 	"github.com/yuhuo/sync-db/ui"
 )
 
 func main() {
 	configFile := flag.String("config", "config.yaml", "Path to config file")
+	exportFile := flag.String("export", "", "Export generated SQL statements to a script file")
 	flag.Parse()
 
 	// 加载配置
@@ -45,12 +47,16 @@ func main() {
 	defer connManager.Close()
 
 	appLogger.Info("Successfully connected to both databases")
+	appLogger.Info(fmt.Sprintf("Source server: %s, target server: %s",
+		connManager.GetSourceDB().Version(), connManager.GetTargetDB().Version()))
 
 	// 第一步：比对差异
-	fmt.Println("\n========== Step 1: Comparing Differences ==========\n")
+	fmt.Print("\n========== Step 1: Comparing Differences ==========\n\n")
 	appLogger.Info("Starting difference comparison")
 
-	comparator := sync.NewComparator(connManager.GetSourceDB(), connManager.GetTargetDB())
+	comparator := sync.NewComparator(connManager.GetSourceDB(), connManager.GetTargetDB(), cfg.Compare).
+		WithRenames(cfg.Renames).
+		WithOrphanTables(cfg.OrphanTables)
 	diff, err := comparator.CompareDifferences(cfg.SyncDataTables)
 	if err != nil {
 		appLogger.Error(fmt.Sprintf("Failed to compare differences: %v", err))
@@ -58,8 +64,35 @@ func main() {
 		os.Exit(1)
 	}
 
-	appLogger.Info(fmt.Sprintf("Comparison complete: %d structure diffs, %d data diffs, %d view diffs",
-		len(diff.StructureDifferences), len(diff.DataDifferences), len(diff.ViewDifferences)))
+	appLogger.Info(fmt.Sprintf("Comparison complete: %d structure diffs, %d data diffs, %d view diffs, %d trigger diffs, %d routine diffs, %d event diffs",
+		len(diff.StructureDifferences), len(diff.DataDifferences), len(diff.ViewDifferences),
+		len(diff.TriggerDifferences), len(diff.RoutineDifferences), len(diff.EventDifferences)))
+	for _, orphan := range diff.OrphanTables {
+		appLogger.Warn(fmt.Sprintf("Table %s exists only in target database (action: %s)", orphan.TableName, orphan.Action))
+	}
+	for _, skipped := range diff.SkippedDataTables {
+		appLogger.Warn(fmt.Sprintf("Data sync skipped for table %s: %s", skipped.TableName, skipped.Reason))
+	}
+	for _, skipped := range diff.SkippedRoutines {
+		appLogger.Warn(fmt.Sprintf("Sync skipped for %s %s: %s", skipped.RoutineType, skipped.RoutineName, skipped.Reason))
+	}
+	for _, structDiff := range diff.StructureDifferences {
+		for _, violation := range structDiff.CheckViolations {
+			if violation.Error != "" {
+				appLogger.Warn(fmt.Sprintf("CHECK constraint %s on table %s skipped: cannot be validated on target: %s",
+					violation.Constraint.Name, structDiff.TableName, violation.Error))
+				continue
+			}
+			appLogger.Warn(fmt.Sprintf("CHECK constraint %s on table %s skipped: %d target row(s) violate it",
+				violation.Constraint.Name, structDiff.TableName, violation.RowCount))
+		}
+		if structDiff.PartitionChange != nil && !cfg.Generate.DropPartitions {
+			for _, part := range structDiff.PartitionChange.PartitionsDropped {
+				appLogger.Warn(fmt.Sprintf("Partition %s of table %s exists only in target database, enable generate.drop_partitions to drop it",
+					part.Name, structDiff.TableName))
+			}
+		}
+	}
 
 	// 展示差异
 	ui.PrintDifferenceSummary(diff)
@@ -70,6 +103,9 @@ func main() {
 		return
 	}
 
+	// 确认推测的改名，未确认的按删除后新增处理
+	confirmRenames(diff, appLogger)
+
 	// 用户确认
 	if !ui.ConfirmContinue("Do you want to continue with the sync?") {
 		fmt.Println("Sync cancelled by user")
@@ -78,21 +114,31 @@ func main() {
 	}
 
 	// 第二步：生成 SQL
-	fmt.Println("\n========== Step 2: Generating SQL Statements ==========\n")
+	fmt.Print("\n========== Step 2: Generating SQL Statements ==========\n\n")
 	appLogger.Info("Generating SQL statements")
 
-	sqlGen := sync.NewSQLGenerator(connManager.GetSourceDB())
-	sqls, err := sqlGen.GenerateSQL(diff)
+	sqlGen := sync.NewSQLGenerator(connManager.GetSourceDB(), connManager.GetTargetDB(), cfg.Generate)
+	stmts, err := sqlGen.GenerateSQL(diff)
 	if err != nil {
 		appLogger.Error(fmt.Sprintf("Failed to generate SQL: %v", err))
 		fmt.Fprintf(os.Stderr, "Failed to generate SQL: %v\n", err)
 		os.Exit(1)
 	}
 
-	appLogger.Info(fmt.Sprintf("Generated %d SQL statements", len(sqls)))
+	appLogger.Info(fmt.Sprintf("Generated %d SQL statements", len(stmts)))
 
 	// 展示 SQL
-	ui.PrintSQLStatements(sqls)
+	ui.PrintSQLStatements(sync.RenderStatements(stmts))
+
+	// 导出 SQL 脚本
+	if *exportFile != "" {
+		if err := exportScript(*exportFile, stmts); err != nil {
+			appLogger.Error(fmt.Sprintf("Failed to export SQL script: %v", err))
+			fmt.Fprintf(os.Stderr, "Failed to export SQL script: %v\n", err)
+			os.Exit(1)
+		}
+		appLogger.Info(fmt.Sprintf("SQL script exported to %s", *exportFile))
+	}
 
 	// 用户确认执行
 	if !ui.ConfirmContinue("Do you want to execute these SQL statements?") {
@@ -102,11 +148,11 @@ func main() {
 	}
 
 	// 第三步：执行 SQL
-	fmt.Println("\n========== Step 3: Executing SQL Statements ==========\n")
+	fmt.Print("\n========== Step 3: Executing SQL Statements ==========\n\n")
 	appLogger.Info("Starting SQL execution")
 
 	executor := sync.NewExecutor(connManager.GetTargetDB(), appLogger)
-	results := executor.ExecuteSQL(sqls)
+	results := executor.ExecuteSQL(stmts)
 
 	total, success, failed := sync.GetSummary(results)
 	appLogger.Info(fmt.Sprintf("SQL execution complete: %d total, %d success, %d failed", total, success, failed))
@@ -124,10 +170,12 @@ func main() {
 	}
 
 	// 第四步：验证
-	fmt.Println("\n========== Step 4: Verifying Sync Results ==========\n")
+	fmt.Print("\n========== Step 4: Verifying Sync Results ==========\n\n")
 	appLogger.Info("Starting verification")
 
-	verifier := sync.NewVerifier(connManager.GetSourceDB(), connManager.GetTargetDB())
+	verifier := sync.NewVerifier(connManager.GetSourceDB(), connManager.GetTargetDB(), cfg.Compare).
+		WithRenames(cfg.Renames).
+		WithOrphanTables(cfg.OrphanTables)
 	verifySuccess, verifyMessage, err := verifier.VerifySync(cfg.SyncDataTables)
 	if err != nil {
 		appLogger.Error(fmt.Sprintf("Failed to verify sync: %v", err))
@@ -146,3 +194,47 @@ func main() {
 		appLogger.Error("Sync verification failed")
 	}
 }
+
+// confirmRenames 逐个询问用户是否接受推测的表改名和列改名
+func confirmRenames(diff *models.SyncDifference, appLogger *logger.Logger) {
+	for i, rename := range diff.TableRenames {
+		if rename.Confirmed {
+			continue
+		}
+		prompt := fmt.Sprintf("Table `%s` looks renamed to `%s`. Rename it instead of creating a new table?",
+			rename.OldName, rename.NewName)
+		if ui.ConfirmContinue(prompt) {
+			diff.ConfirmTableRename(i)
+			appLogger.Info(fmt.Sprintf("Table rename confirmed: %s -> %s", rename.OldName, rename.NewName))
+		}
+	}
+
+	for i := range diff.StructureDifferences {
+		structDiff := &diff.StructureDifferences[i]
+		for j, rename := range structDiff.ColumnsRenamed {
+			if rename.Confirmed {
+				continue
+			}
+			prompt := fmt.Sprintf("Column `%s`.`%s` looks renamed to `%s`. Rename it instead of DROP + ADD?",
+				structDiff.TableName, rename.OldName, rename.NewName)
+			if ui.ConfirmContinue(prompt) {
+				structDiff.ConfirmColumnRename(j)
+				appLogger.Info(fmt.Sprintf("Column rename confirmed: %s.%s -> %s", structDiff.TableName, rename.OldName, rename.NewName))
+			}
+		}
+	}
+}
+
+// exportScript 将生成的 SQL 语句导出到脚本文件
+func exportScript(path string, stmts []models.Statement) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create script file: %w", err)
+	}
+	defer file.Close()
+
+	if err := sync.WriteScript(file, stmts); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,8 @@
+package models
+
+// CheckConstraint 表示表的 CHECK 约束
+type CheckConstraint struct {
+	Name       string
+	Expression string // 约束表达式（CHECK_CLAUSE）
+	Enforced   bool   // 是否强制执行（NOT ENFORCED 的约束只记录不检查）
+}
@@ -1,17 +1,53 @@
 package models
 
+import (
+	"regexp"
+	"strings"
+)
+
 // Column 表示数据库表的列
 type Column struct {
-	Name             string
-	Type             string // VARCHAR, INT, etc.
-	Length           int    // for VARCHAR(255), this is 255, 0 if not applicable
-	IsNullable       bool
-	DefaultValue     *string
-	IsAutoIncrement  bool
-	Charset          *string // MySQL specific
-	Collation        *string // MySQL specific
-	Extra            string  // auto_increment, on update CURRENT_TIMESTAMP, etc.
-	Comment          *string // Column comment
+	Name                 string
+	Position             int    // 列在表中的位置（ORDINAL_POSITION），从 1 开始
+	Type                 string // VARCHAR, INT, etc.
+	Length               int    // for VARCHAR(255), this is 255, 0 if not applicable
+	IsNullable           bool
+	DefaultValue         *string // 默认值，DefaultIsExpression 为 true 时为表达式（不含外层括号）
+	DefaultIsExpression  bool    // 默认值是表达式（如 CURRENT_TIMESTAMP、uuid()）而不是字面量
+	OnUpdate             string  // ON UPDATE 子句的值，如 CURRENT_TIMESTAMP(3)，没有时为空
+	IsAutoIncrement      bool
+	Charset              *string // MySQL specific
+	Collation            *string // MySQL specific
+	Extra                string  // auto_increment, on update CURRENT_TIMESTAMP, etc.
+	Comment              *string // Column comment
+	GenerationExpression string  // 生成列的表达式，普通列为空
+	GeneratedStorage     string  // 生成列的存储方式：VIRTUAL, STORED；普通列为空
+}
+
+// IsGenerated 判断是否为生成列（生成列的值由表达式计算，不能写入）
+func (c *Column) IsGenerated() bool {
+	return c.GeneratedStorage != ""
+}
+
+// currentTimestampPattern 匹配 CURRENT_TIMESTAMP 及其同义写法，可带精度
+var currentTimestampPattern = regexp.MustCompile(`(?i)^(current_timestamp|now|localtime|localtimestamp)(\((\d*)\))?$`)
+
+// IsCurrentTimestamp 判断表达式是否为 CURRENT_TIMESTAMP（含 NOW()、LOCALTIMESTAMP 等同义写法）
+func IsCurrentTimestamp(expr string) bool {
+	return currentTimestampPattern.MatchString(strings.TrimSpace(expr))
+}
+
+// NormalizeCurrentTimestamp 将 CURRENT_TIMESTAMP 的同义写法统一为 CURRENT_TIMESTAMP 或 CURRENT_TIMESTAMP(n)
+// 其他表达式原样返回
+func NormalizeCurrentTimestamp(expr string) string {
+	match := currentTimestampPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if match == nil {
+		return expr
+	}
+	if match[3] == "" || match[3] == "0" {
+		return "CURRENT_TIMESTAMP"
+	}
+	return "CURRENT_TIMESTAMP(" + match[3] + ")"
 }
 
 // String 返回列的字符串表示
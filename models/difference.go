@@ -1,56 +1,309 @@
 package models
 
+// 列修改的兼容性分类
+const (
+	ChangeWidening     = "WIDENING"     // 现有数据可以无损保留
+	ChangeNarrowing    = "NARROWING"    // 范围、长度或精度缩小，可能截断数据
+	ChangeIncompatible = "INCOMPATIBLE" // 跨类型族转换，结果取决于具体数据
+)
+
 // ColumnModification 表示列的修改
 type ColumnModification struct {
-	ColumnName string
-	OldColumn  Column
-	NewColumn  Column
+	ColumnName    string
+	OldColumn     Column
+	NewColumn     Column
+	Compatibility string // ChangeWidening, ChangeNarrowing, ChangeIncompatible
+}
+
+// IndexModification 表示同名索引的定义变化
+type IndexModification struct {
+	IndexName string
+	OldIndex  Index
+	NewIndex  Index
+}
+
+// IndexRename 表示定义相同但名称不同的索引
+type IndexRename struct {
+	OldName string
+	NewName string
+	Index   Index
 }
 
 // StructureDifference 表示表结构的差异
 type StructureDifference struct {
 	TableName          string
-	IsNewTable         bool                 // 标记：表是否在源库存在但在目标库不存在
-	TableDefinition    *TableDefinition     // 完整的表定义（仅当新表时非空）
-	ColumnsAdded       []Column             // 新增的列（完整定义）
-	ColumnsDeleted     []string             // 删除的列名
+	IsNewTable         bool             // 标记：表是否在源库存在但在目标库不存在
+	RenamedFrom        string           // 已确认改名时目标库中的旧表名，其余差异在改名之后的表上执行
+	TableDefinition    *TableDefinition // 完整的表定义（仅当新表时非空）
+	ColumnsAdded       []Column         // 新增的列（完整定义）
+	ColumnsDeleted     []string         // 删除的列名
 	ColumnsModified    []ColumnModification
+	ColumnsRenamed     []ColumnRename // 改名的列（未确认的改名对应的列仍在新增和删除中）
+	ColumnsReordered   []Column       // 仅位置不同的列（目标库中的当前定义），按源库顺序排列
+	ColumnOrder        []string       // 源库的列顺序，用于确定新增和移动的列的位置
 	IndexesAdded       []Index
 	IndexesDeleted     []Index
+	IndexesModified    []IndexModification   // 同名但定义不同的索引
+	IndexesRenamed     []IndexRename         // 定义相同但改名的索引
+	ForeignKeysAdded   []ForeignKey          // 新增的外键（定义变化的外键同时出现在新增和删除中）
+	ForeignKeysDeleted []ForeignKey          // 删除的外键
+	ChecksAdded        []CheckConstraint     // 新增的 CHECK 约束（表达式变化的约束同时出现在新增和删除中）
+	ChecksDeleted      []CheckConstraint     // 删除的 CHECK 约束
+	CheckViolations    []CheckViolation      // 目标库现有数据不满足的新增约束，这些约束不会生成 SQL
+	OptionsModified    []TableOptionChange   // 表选项的变化
+	PartitionChange    *PartitionDifference  // 分区定义的变化，nil 表示没有变化
+	CharsetChanges     []ColumnCharsetChange // 仅字符集或排序规则不同的列（需要开启字符集比对）
+}
+
+// ColumnCharsetChange 表示仅字符集或排序规则不同的列
+type ColumnCharsetChange struct {
+	ColumnName   string
+	OldCollation string // 目标库中的排序规则
+	NewCollation string // 源库中的排序规则
+	NewColumn    Column // 源库中的定义
+}
+
+// PartitionDifference 表示分区定义的差异
+// 分区方式或表达式不同时整体重新分区；相同时 RANGE/LIST 分区按分区名逐个比对
+type PartitionDifference struct {
+	OldPartitioning       *Partitioning // 目标库中的分区定义，nil 表示未分区
+	NewPartitioning       *Partitioning // 源库中的分区定义，nil 表示未分区
+	Repartition           bool          // 需要按源库的定义重新分区（或移除分区），会重建表
+	PartitionsDropped     []Partition   // 只在目标库存在的分区，删除分区会同时删除其中的数据
+	PartitionsReorganized []PartitionReorganize
+	PartitionsAdded       []Partition // 只在源库存在的分区
+}
+
+// PartitionReorganize 表示将目标库的一组分区重组为源库中的分区，数据随之迁移
+type PartitionReorganize struct {
+	From []string    // 目标库中被重组的分区名
+	Into []Partition // 源库中对应的分区定义
+}
+
+// TableOptionChange 表示一项表选项的变化
+type TableOptionChange struct {
+	Option   string // ALTER TABLE 中的选项，如 ENGINE、COMMENT、CONVERT TO CHARACTER SET
+	OldValue string
+	NewValue string
+	Rebuild  bool // 修改该选项会重建表（复制全部数据）
+}
+
+// CheckViolation 表示目标库中不满足新增 CHECK 约束的数据，或无法在目标库验证的约束
+type CheckViolation struct {
+	Constraint CheckConstraint
+	RowCount   int64                    // 违反约束的行数
+	SampleRows []map[string]interface{} // 部分违反约束的行，用于展示
+	Error      string                   // 无法验证的原因（如表达式引用了本次新增的列），为空表示已验证
+}
+
+// ColumnRename 表示列改名，来自配置中的声明或按定义和位置推测
+type ColumnRename struct {
+	OldName   string
+	NewName   string
+	OldColumn Column // 目标库中的定义
+	NewColumn Column // 源库中的定义
+	Confirmed bool   // 在配置中声明或经用户确认；未确认的改名按删除旧列、新增新列处理
+}
+
+// ConfirmColumnRename 确认第 i 个推测的列改名，对应的列不再作为删除和新增处理
+func (s *StructureDifference) ConfirmColumnRename(i int) {
+	rename := &s.ColumnsRenamed[i]
+	if rename.Confirmed {
+		return
+	}
+	rename.Confirmed = true
+
+	for j, name := range s.ColumnsDeleted {
+		if name == rename.OldName {
+			s.ColumnsDeleted = append(s.ColumnsDeleted[:j:j], s.ColumnsDeleted[j+1:]...)
+			break
+		}
+	}
+	for j, col := range s.ColumnsAdded {
+		if col.Name == rename.NewName {
+			s.ColumnsAdded = append(s.ColumnsAdded[:j:j], s.ColumnsAdded[j+1:]...)
+			break
+		}
+	}
+}
+
+// ConfirmedColumnRenames 返回已确认的列改名
+func (s *StructureDifference) ConfirmedColumnRenames() []ColumnRename {
+	var renames []ColumnRename
+	for _, rename := range s.ColumnsRenamed {
+		if rename.Confirmed {
+			renames = append(renames, rename)
+		}
+	}
+	return renames
+}
+
+// ChangeCount 返回表结构变更的数量
+func (s *StructureDifference) ChangeCount() int {
+	count := 0
+	if s.RenamedFrom != "" {
+		count = 1
+	}
+	return count + len(s.ColumnsAdded) + len(s.ColumnsDeleted) + len(s.ColumnsModified) + len(s.ColumnsReordered) +
+		len(s.ConfirmedColumnRenames()) +
+		len(s.IndexesAdded) + len(s.IndexesDeleted) + len(s.IndexesModified) + len(s.IndexesRenamed) +
+		len(s.ForeignKeysAdded) + len(s.ForeignKeysDeleted) + len(s.ChecksAdded) + len(s.ChecksDeleted) +
+		len(s.OptionsModified) + s.PartitionChange.ChangeCount() + len(s.CharsetChanges)
+}
+
+// ChangeCount 返回分区变更的数量
+func (p *PartitionDifference) ChangeCount() int {
+	if p == nil {
+		return 0
+	}
+	if p.Repartition {
+		return 1
+	}
+	return len(p.PartitionsDropped) + len(p.PartitionsReorganized) + len(p.PartitionsAdded)
 }
 
+// 数据比对时定位行的方式
+const (
+	MatchByPrimaryKey = "PRIMARY KEY" // 按主键
+	MatchByUniqueKey  = "UNIQUE KEY"  // 按全部列非空的唯一索引
+	MatchByRowHash    = "ROW HASH"    // 按整行内容（多重集语义，不产生 UPDATE）
+)
+
 // DataDifference 表示表数据的差异
 type DataDifference struct {
-	TableName      string
-	RowsToInsert   []map[string]interface{} // 新增行
-	RowsToDelete   []map[string]interface{} // 删除行
-	RowsToUpdate   []UpdateRow              // 修改行
-	PrimaryKeyName string                   // 主键列名
+	TableName        string
+	RowsToInsert     []map[string]interface{} // 新增行
+	RowsToDelete     []map[string]interface{} // 删除行
+	RowsToUpdate     []UpdateRow              // 修改行
+	KeyColumns       []string                 // 用于定位行的列（主键/唯一键按索引顺序，整行哈希时为全部非生成列）
+	MatchMode        string                   // 定位行的方式：MatchByPrimaryKey, MatchByUniqueKey, MatchByRowHash
+	GeneratedColumns []string                 // 生成列，INSERT 和 UPDATE 时不写入
+}
+
+// SkippedTable 表示配置了数据同步但未能比对数据的表
+type SkippedTable struct {
+	TableName string
+	Reason    string
+}
+
+// SkippedRoutine 表示未能比对的存储过程或函数
+type SkippedRoutine struct {
+	RoutineName string
+	RoutineType string
+	Reason      string
 }
 
 // UpdateRow 表示一行数据的更新
 type UpdateRow struct {
-	PrimaryKeyValue interface{}
-	OldValues       map[string]interface{}
-	NewValues       map[string]interface{}
+	Key       RowKey // 行的键值，与 KeyColumns 一一对应
+	OldValues map[string]interface{}
+	NewValues map[string]interface{}
 }
 
 // ViewDifference 表示视图的差异
 type ViewDifference struct {
-	ViewName     string
-	Operation    string // CREATE, DROP, MODIFY
+	ViewName      string
+	Operation     string // CREATE, DROP, MODIFY
 	OldDefinition string
 	NewDefinition string
 }
 
+// TriggerDifference 表示触发器的差异
+type TriggerDifference struct {
+	TriggerName string
+	TableName   string
+	Operation   string             // CREATE, DROP, MODIFY
+	OldTrigger  *TriggerDefinition // 目标库中的定义（CREATE 时为空）
+	NewTrigger  *TriggerDefinition // 源库中的定义（DROP 时为空）
+}
+
+// RoutineDifference 表示存储过程或函数的差异
+type RoutineDifference struct {
+	RoutineName string
+	RoutineType string             // PROCEDURE, FUNCTION
+	Operation   string             // CREATE, DROP, MODIFY
+	OldRoutine  *RoutineDefinition // 目标库中的定义（CREATE 时为空）
+	NewRoutine  *RoutineDefinition // 源库中的定义（DROP 时为空）
+}
+
+// EventDifference 表示定时事件的差异
+type EventDifference struct {
+	EventName string
+	Operation string           // CREATE, DROP, MODIFY
+	OldEvent  *EventDefinition // 目标库中的定义（CREATE 时为空）
+	NewEvent  *EventDefinition // 源库中的定义（DROP 时为空）
+}
+
+// TableRename 表示表改名，来自配置中的声明或按表结构推测
+type TableRename struct {
+	OldName   string // 目标库中的表名
+	NewName   string // 源库中的表名
+	Confirmed bool   // 在配置中声明或经用户确认；未确认的改名按新建表处理，旧表保留
+	// Difference 推测的改名确认后，新表相对旧表的其余差异（如表选项、分区），在改名之后执行
+	Difference *StructureDifference
+}
+
+// 目标库独有的表的处理方式
+const (
+	OrphanReport  = "REPORT"  // 仅报告
+	OrphanDrop    = "DROP"    // 删除
+	OrphanArchive = "ARCHIVE" // 改名归档
+)
+
+// OrphanTable 表示只存在于目标库的表
+type OrphanTable struct {
+	TableName string
+	Action    string // OrphanReport, OrphanDrop, OrphanArchive
+}
+
 // SyncDifference 表示全部差异的汇总
 type SyncDifference struct {
 	StructureDifferences []StructureDifference
+	TableRenames         []TableRename
+	OrphanTables         []OrphanTable             // 只存在于目标库的表（改名的旧表除外）
 	DataDifferences      map[string]DataDifference // key: table name
 	ViewDifferences      []ViewDifference
+	TriggerDifferences   []TriggerDifference
+	RoutineDifferences   []RoutineDifference
+	EventDifferences     []EventDifference
+	SkippedDataTables    []SkippedTable   // 跳过数据比对的表及原因
+	SkippedRoutines      []SkippedRoutine // 跳过比对的存储过程和函数及原因
+}
+
+// ConfirmTableRename 确认第 i 个推测的表改名
+// 推测的改名要求两边表结构相同，确认后新表不再需要创建，只需把旧表改名
+func (s *SyncDifference) ConfirmTableRename(i int) {
+	rename := &s.TableRenames[i]
+	if rename.Confirmed {
+		return
+	}
+	rename.Confirmed = true
+
+	// 旧表已改名，不再是目标库独有的表
+	for j, orphan := range s.OrphanTables {
+		if orphan.TableName == rename.OldName {
+			s.OrphanTables = append(s.OrphanTables[:j:j], s.OrphanTables[j+1:]...)
+			break
+		}
+	}
+
+	for j := range s.StructureDifferences {
+		structDiff := &s.StructureDifferences[j]
+		if structDiff.TableName == rename.NewName {
+			if rename.Difference != nil {
+				*structDiff = *rename.Difference
+			} else {
+				*structDiff = StructureDifference{TableName: rename.NewName}
+			}
+			structDiff.RenamedFrom = rename.OldName
+			break
+		}
+	}
 }
 
 // HasDifferences 检查是否有任何差异
 func (s *SyncDifference) HasDifferences() bool {
-	return len(s.StructureDifferences) > 0 || len(s.DataDifferences) > 0 || len(s.ViewDifferences) > 0
+	return len(s.StructureDifferences) > 0 || len(s.TableRenames) > 0 || len(s.OrphanTables) > 0 || len(s.DataDifferences) > 0 || len(s.ViewDifferences) > 0 ||
+		len(s.TriggerDifferences) > 0 || len(s.RoutineDifferences) > 0 ||
+		len(s.EventDifferences) > 0
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// EventDefinition 表示数据库定时事件的定义
+type EventDefinition struct {
+	EventName     string
+	EventType     string     // ONE TIME, RECURRING
+	ExecuteAt     *time.Time // 一次性事件的执行时间
+	IntervalValue string     // 周期事件的间隔值，如 "1"、"1:30"
+	IntervalField string     // 周期事件的间隔单位，如 DAY、HOUR_MINUTE
+	Starts        *time.Time // 周期事件的开始时间
+	Ends          *time.Time // 周期事件的结束时间（为空表示不结束）
+	Status        string     // ENABLED, DISABLED, SLAVESIDE_DISABLED
+	OnCompletion  string     // PRESERVE, NOT PRESERVE
+	Comment       string
+	Body          string // 事件语句（EVENT_DEFINITION）
+	TimeZone      string // 事件的时区，ExecuteAt、Starts、Ends 都是该时区下的时间
+}
@@ -0,0 +1,12 @@
+package models
+
+// ForeignKey 表示表的外键约束
+type ForeignKey struct {
+	Name              string
+	Columns           []string // 外键列，按约束中的顺序
+	ReferencedSchema  string   // 被引用表所在的库，与当前库相同时为空
+	ReferencedTable   string
+	ReferencedColumns []string // 被引用的列，与 Columns 一一对应
+	OnDelete          string   // RESTRICT, CASCADE, SET NULL, NO ACTION, SET DEFAULT
+	OnUpdate          string
+}
@@ -2,12 +2,34 @@ package models
 
 // Index 表示数据库表的索引
 type Index struct {
-	Name    string   // 索引名
-	Type    string   // PRIMARY, UNIQUE, INDEX
-	Columns []string // 组成索引的列名
+	Name    string      // 索引名
+	Type    string      // PRIMARY, UNIQUE, FULLTEXT, SPATIAL, INDEX
+	Columns []string    // 组成索引的列名（不含函数索引的表达式部分）
+	Parts   []IndexPart // 索引的各组成部分，按 SEQ_IN_INDEX 排序
+	Method  string      // 索引方法（INDEX_TYPE），如 BTREE, HASH
+	Visible bool        // 是否可见（MySQL 8.0 的 INVISIBLE 索引 / MariaDB 的 IGNORED 索引为 false）
+	Comment string      // 索引注释
+}
+
+// IndexPart 表示索引的一个组成部分（列或表达式）
+type IndexPart struct {
+	Column     string // 列名，函数索引部分为空
+	Expression string // 函数索引的表达式（MySQL 8.0.13+），普通列为空
+	SubPart    int    // 前缀索引的长度，0 表示使用整列
+	Descending bool   // 是否降序（MySQL 8.0+）
 }
 
 // Key 返回索引的唯一键
 func (i *Index) Key() string {
 	return i.Name
 }
+
+// HasExpression 检查索引是否包含函数表达式部分
+func (i *Index) HasExpression() bool {
+	for _, part := range i.Parts {
+		if part.Expression != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Partitioning 表示表的分区方式（不包含子分区）
+type Partitioning struct {
+	Method     string // RANGE, RANGE COLUMNS, LIST, LIST COLUMNS, HASH, LINEAR HASH, KEY, LINEAR KEY
+	Expression string // 分区表达式或分区列（PARTITION_EXPRESSION）
+	Partitions []Partition
+}
+
+// Partition 表示一个分区
+type Partition struct {
+	Name        string
+	Description string // RANGE 分区的上界或 LIST 分区的取值列表（PARTITION_DESCRIPTION），HASH/KEY 分区为空
+}
+
+// IsRange 判断是否为 RANGE 或 RANGE COLUMNS 分区
+func (p *Partitioning) IsRange() bool {
+	return strings.HasPrefix(p.Method, "RANGE")
+}
+
+// IsList 判断是否为 LIST 或 LIST COLUMNS 分区
+func (p *Partitioning) IsList() bool {
+	return strings.HasPrefix(p.Method, "LIST")
+}
+
+// PartitionDefinition 构建单个分区的定义，如 "PARTITION `p2024` VALUES LESS THAN (739252)"
+func (p *Partitioning) PartitionDefinition(part Partition) string {
+	def := fmt.Sprintf("PARTITION `%s`", part.Name)
+	switch {
+	case p.IsRange() && part.Description == "MAXVALUE":
+		def += " VALUES LESS THAN MAXVALUE"
+	case p.IsRange():
+		def += fmt.Sprintf(" VALUES LESS THAN (%s)", part.Description)
+	case p.IsList():
+		def += fmt.Sprintf(" VALUES IN (%s)", part.Description)
+	}
+	return def
+}
+
+// Clause 构建完整的分区子句，如 "PARTITION BY RANGE (expr) (PARTITION ..., ...)"
+func (p *Partitioning) Clause() string {
+	defs := make([]string, len(p.Partitions))
+	for i, part := range p.Partitions {
+		defs[i] = p.PartitionDefinition(part)
+	}
+	return fmt.Sprintf("PARTITION BY %s (%s) (%s)", p.Method, p.Expression, strings.Join(defs, ", "))
+}
+
+// PartitionNames 返回所有分区名
+func (p *Partitioning) PartitionNames() []string {
+	names := make([]string, len(p.Partitions))
+	for i, part := range p.Partitions {
+		names[i] = part.Name
+	}
+	return names
+}
@@ -0,0 +1,21 @@
+package models
+
+// 存储程序类型
+const (
+	RoutineProcedure = "PROCEDURE"
+	RoutineFunction  = "FUNCTION"
+)
+
+// RoutineDefinition 表示存储过程或函数的定义
+type RoutineDefinition struct {
+	RoutineName  string
+	RoutineType  string // RoutineProcedure 或 RoutineFunction
+	SecurityType string // DEFINER, INVOKER
+	CreateSQL    string // SHOW CREATE PROCEDURE/FUNCTION 的结果，已去除 DEFINER 子句
+	Hidden       bool   // 没有权限查看定义（SHOW CREATE 的结果为 NULL），CreateSQL 为空
+}
+
+// Key 返回存储程序的唯一标识，存储过程和函数的名字空间相互独立
+func (r RoutineDefinition) Key() string {
+	return r.RoutineType + " " + r.RoutineName
+}
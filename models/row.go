@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RowKey 表示一行数据的键值（如主键），按键列顺序排列
+type RowKey []interface{}
+
+// RowKeyOf 从行数据中按列顺序提取键值
+func RowKeyOf(row map[string]interface{}, columns []string) RowKey {
+	key := make(RowKey, len(columns))
+	for i, col := range columns {
+		key[i] = row[col]
+	}
+	return key
+}
+
+// String 返回键值的规范化字符串表示，可用作 map 的键
+// 不同驱动返回的 []byte 与 string、int64 与 []byte("1") 会得到相同的结果
+func (k RowKey) String() string {
+	parts := make([]string, len(k))
+	for i, val := range k {
+		switch v := val.(type) {
+		case nil:
+			parts[i] = "NULL"
+		case []byte:
+			parts[i] = fmt.Sprintf("%q", string(v))
+		case string:
+			parts[i] = fmt.Sprintf("%q", v)
+		case time.Time:
+			parts[i] = fmt.Sprintf("%q", v.UTC().Format("2006-01-02 15:04:05.999999999"))
+		default:
+			parts[i] = fmt.Sprintf("%q", fmt.Sprintf("%v", v))
+		}
+	}
+	return strings.Join(parts, ",")
+}
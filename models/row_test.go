@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRowKeyOf(t *testing.T) {
+	row := map[string]interface{}{"role_id": int64(2), "user_id": int64(1), "note": "x"}
+
+	key := RowKeyOf(row, []string{"user_id", "role_id"})
+	if len(key) != 2 || key[0] != int64(1) || key[1] != int64(2) {
+		t.Errorf("Expected key [1 2] in column order, got %v", key)
+	}
+	if other := RowKeyOf(row, []string{"role_id", "user_id"}); other.String() == key.String() {
+		t.Errorf("Expected column order to matter, got %s for both", key.String())
+	}
+}
+
+func TestRowKeyString(t *testing.T) {
+	at := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		key1, key2 RowKey
+		equal      bool
+	}{
+		{RowKey{nil}, RowKey{""}, false},
+		{RowKey{nil}, RowKey{"NULL"}, false},
+		{RowKey{[]byte("abc")}, RowKey{"abc"}, true},
+		{RowKey{int64(1)}, RowKey{[]byte("1")}, true},
+		{RowKey{"a,b", "c"}, RowKey{"a", "b,c"}, false},
+		{RowKey{at}, RowKey{at.In(time.FixedZone("CST", 8*3600))}, true},
+		{RowKey{at}, RowKey{at.Add(time.Microsecond)}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.key1.String() == tt.key2.String(); got != tt.equal {
+			t.Errorf("Expected %#v and %#v equal=%v, got %s and %s", tt.key1, tt.key2, tt.equal, tt.key1.String(), tt.key2.String())
+		}
+	}
+}
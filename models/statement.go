@@ -0,0 +1,44 @@
+package models
+
+// 语句类型
+const (
+	StatementCreateTable   = "CREATE TABLE"
+	StatementAlterTable    = "ALTER TABLE"
+	StatementRenameTable   = "RENAME TABLE"
+	StatementDropTable     = "DROP TABLE"
+	StatementCreateView    = "CREATE VIEW"
+	StatementDropView      = "DROP VIEW"
+	StatementCreateTrigger = "CREATE TRIGGER"
+	StatementDropTrigger   = "DROP TRIGGER"
+	StatementCreateRoutine = "CREATE ROUTINE"
+	StatementDropRoutine   = "DROP ROUTINE"
+	StatementCreateEvent   = "CREATE EVENT"
+	StatementAlterEvent    = "ALTER EVENT"
+	StatementDropEvent     = "DROP EVENT"
+	StatementInsert        = "INSERT"
+	StatementUpdate        = "UPDATE"
+	StatementDelete        = "DELETE"
+)
+
+// Statement 表示一条需要在目标库执行的 SQL 语句
+// 数据语句中的值以 ? 占位并通过 Args 传递，执行时交给驱动绑定参数，不在 SQL 中拼接字面量
+type Statement struct {
+	Kind   string        // 语句类型，如 StatementAlterTable、StatementInsert
+	Table  string        // 所属的表名（视图语句为视图名）
+	Object string        // 受影响的对象，如 "column `name`"、"index `idx_name`"、"row (`id` = 1)"
+	SQL    string        // SQL 模板，不含结尾的分号
+	Args   []interface{} // 与 SQL 中占位符一一对应的参数
+	// TimeZone 执行时使用的会话时区，为空表示不修改
+	// 定时事件中的时间按会话时区解释，会话时区也会记录为事件的时区
+	TimeZone string
+}
+
+// IsCompound 判断语句是否可能包含以分号分隔的复合语句体（BEGIN ... END）
+// 这类语句在 mysql 客户端脚本中需要临时切换 DELIMITER
+func (s Statement) IsCompound() bool {
+	switch s.Kind {
+	case StatementCreateTrigger, StatementCreateRoutine, StatementCreateEvent, StatementAlterEvent:
+		return true
+	}
+	return false
+}
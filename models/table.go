@@ -1,13 +1,32 @@
 package models
 
+import "strings"
+
 // TableDefinition 表示数据库表的完整定义
 type TableDefinition struct {
-	TableName  string
-	Columns    []Column
-	Indexes    []Index
-	PrimaryKey string // 主键列名
-	Charset    *string
-	Collation  *string
+	TableName        string
+	Columns          []Column
+	Indexes          []Index
+	ForeignKeys      []ForeignKey
+	CheckConstraints []CheckConstraint
+	PrimaryKey       []string // 主键列名（按主键中的顺序）
+	Charset          *string
+	Collation        *string
+	Engine           string // 存储引擎，如 InnoDB
+	RowFormat        string // 实际使用的行格式，如 Dynamic、Compressed
+	Comment          string
+	KeyBlockSize     int           // 压缩页大小（KB），0 表示未指定
+	Compression      string        // 页压缩算法，如 zlib、lz4，空表示未指定
+	AutoIncrement    uint64        // 下一个自增值，0 表示没有自增列
+	Partitioning     *Partitioning // 分区定义，nil 表示未分区
+}
+
+// CharsetOfCollation 返回排序规则所属的字符集，如 utf8mb4_0900_ai_ci → utf8mb4
+func CharsetOfCollation(collation string) string {
+	if i := strings.Index(collation, "_"); i > 0 {
+		return collation[:i]
+	}
+	return collation
 }
 
 // GetColumnByName 根据列名获取列定义
@@ -22,5 +41,27 @@ func (t *TableDefinition) GetColumnByName(name string) *Column {
 
 // HasPrimaryKey 检查是否有主键
 func (t *TableDefinition) HasPrimaryKey() bool {
-	return t.PrimaryKey != ""
+	return len(t.PrimaryKey) > 0
+}
+
+// NotNullUniqueKey 返回第一个所有列均为 NOT NULL 的唯一索引，可作为无主键表的行标识
+func (t *TableDefinition) NotNullUniqueKey() *Index {
+	for i := range t.Indexes {
+		idx := &t.Indexes[i]
+		if idx.Type != "UNIQUE" || idx.HasExpression() {
+			continue
+		}
+		usable := true
+		for _, colName := range idx.Columns {
+			col := t.GetColumnByName(colName)
+			if col == nil || col.IsNullable {
+				usable = false
+				break
+			}
+		}
+		if usable {
+			return idx
+		}
+	}
+	return nil
 }
@@ -0,0 +1,11 @@
+package models
+
+// TriggerDefinition 表示数据库触发器的定义
+type TriggerDefinition struct {
+	TriggerName string
+	TableName   string // 触发器所属的表
+	Timing      string // BEFORE, AFTER
+	Event       string // INSERT, UPDATE, DELETE
+	ActionOrder int    // 同一表、同一时机和事件的多个触发器中的执行顺序，从 1 开始
+	Body        string // 触发器语句（ACTION_STATEMENT）
+}
@@ -0,0 +1,121 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// 校验分块大小的调整范围
+const (
+	minChecksumChunkSize = 100
+	maxChecksumChunkSize = 1000000
+)
+
+// compareTableDataByChecksum 按键范围分块校验比对表数据
+// 先在两边分别计算每个键范围的行数和聚合校验值，只有校验不一致的块才逐行读取比对，
+// 适合数据量大但差异很少的表。分块大小根据校验查询的实际耗时自动调整。
+// columns 为参与校验的存储列，两边都必须存在
+func (c *Comparator) compareTableDataByChecksum(tableDef *models.TableDefinition, columns, keyColumns []string, matchMode string) (models.DataDifference, error) {
+	tableName := tableDef.TableName
+	comparators := c.columnComparators(tableDef)
+	diff := models.DataDifference{
+		TableName:    tableName,
+		KeyColumns:   keyColumns,
+		MatchMode:    matchMode,
+		RowsToInsert: []map[string]interface{}{},
+		RowsToDelete: []map[string]interface{}{},
+		RowsToUpdate: []models.UpdateRow{},
+	}
+
+	chunkSize := c.options.ChecksumChunkSize
+	targetDuration := time.Duration(c.options.ChecksumTargetMs) * time.Millisecond
+
+	var lowerBound models.RowKey
+	for {
+		// 以源库的键分布确定本块的上界，nil 表示延伸到表尾
+		upperBound, err := c.sourceQueryHelper.GetKeyBoundary(tableName, keyColumns, lowerBound, chunkSize)
+		if err != nil {
+			return diff, err
+		}
+
+		start := time.Now()
+		sourceChecksum, err := c.sourceQueryHelper.GetChunkChecksum(tableName, columns, keyColumns, lowerBound, upperBound)
+		if err != nil {
+			return diff, err
+		}
+		sourceElapsed := time.Since(start)
+
+		start = time.Now()
+		targetChecksum, err := c.targetQueryHelper.GetChunkChecksum(tableName, columns, keyColumns, lowerBound, upperBound)
+		if err != nil {
+			return diff, err
+		}
+		targetElapsed := time.Since(start)
+
+		if sourceChecksum != targetChecksum {
+			if err := c.compareKeyRange(&diff, lowerBound, upperBound, comparators); err != nil {
+				return diff, err
+			}
+		}
+
+		if upperBound == nil {
+			break
+		}
+		lowerBound = upperBound
+		chunkSize = adjustChunkSize(chunkSize, max(sourceElapsed, targetElapsed), targetDuration)
+	}
+
+	return diff, nil
+}
+
+// checksumColumns 返回参与校验的存储列（按源库顺序），两边的存储列不一致时返回 false
+// 一边独有的列无法在另一边计算校验值，只比对共同列又会漏掉该列的数据差异
+func checksumColumns(sourceColumns, targetColumns []models.Column) ([]string, bool) {
+	sourceStored := getStoredColumnNames(sourceColumns)
+	targetStored := make(map[string]bool)
+	for _, name := range getStoredColumnNames(targetColumns) {
+		targetStored[name] = true
+	}
+	if len(sourceStored) != len(targetStored) {
+		return nil, false
+	}
+	for _, name := range sourceStored {
+		if !targetStored[name] {
+			return nil, false
+		}
+	}
+	return sourceStored, true
+}
+
+// compareKeyRange 逐行读取并比对键范围 (lower, upper] 内的数据
+func (c *Comparator) compareKeyRange(diff *models.DataDifference, lower, upper models.RowKey, comparators map[string]ValueComparator) error {
+	sourceIter := c.sourceQueryHelper.NewRowIterator(diff.TableName, diff.KeyColumns, c.options.ChunkSize).
+		WithRange(lower, upper)
+
+	var sourceRows []map[string]interface{}
+	for sourceIter.Next() {
+		sourceRows = append(sourceRows, sourceIter.Row())
+	}
+	if err := sourceIter.Err(); err != nil {
+		return err
+	}
+
+	targetIter := c.targetQueryHelper.NewRowIterator(diff.TableName, diff.KeyColumns, c.options.ChunkSize).
+		WithRange(lower, upper)
+	return mergeKeyRange(diff, sourceRows, targetIter, comparators)
+}
+
+// adjustChunkSize 根据上一块的校验耗时调整分块大小，使每块耗时接近目标值
+// 单次调整幅度限制在 0.5 到 2 倍之间，避免偶发的慢查询导致分块大小剧烈波动
+func adjustChunkSize(chunkSize int, elapsed, target time.Duration) int {
+	if elapsed <= 0 {
+		return min(chunkSize*2, maxChecksumChunkSize)
+	}
+
+	factor := float64(target) / float64(elapsed)
+	factor = max(0.5, min(factor, 2))
+
+	newSize := int(float64(chunkSize) * factor)
+	return max(minChecksumChunkSize, min(newSize, maxChecksumChunkSize))
+}
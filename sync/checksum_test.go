@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestAdjustChunkSize(t *testing.T) {
+	target := 500 * time.Millisecond
+	tests := []struct {
+		name      string
+		chunkSize int
+		elapsed   time.Duration
+		expected  int
+	}{
+		{"on target", 10000, 500 * time.Millisecond, 10000},
+		{"faster grows proportionally", 10000, 400 * time.Millisecond, 12500},
+		{"much faster grows at most 2x", 10000, 10 * time.Millisecond, 20000},
+		{"no measurable time doubles", 10000, 0, 20000},
+		{"slower shrinks proportionally", 10000, 800 * time.Millisecond, 6250},
+		{"much slower shrinks at most 0.5x", 10000, 10 * time.Second, 5000},
+		{"never below minimum", 150, 10 * time.Second, minChecksumChunkSize},
+		{"never above maximum", 800000, 10 * time.Millisecond, maxChecksumChunkSize},
+		{"zero elapsed at maximum", maxChecksumChunkSize, 0, maxChecksumChunkSize},
+	}
+
+	for _, tt := range tests {
+		if got := adjustChunkSize(tt.chunkSize, tt.elapsed, target); got != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestChecksumColumns(t *testing.T) {
+	columns := func(names ...string) []models.Column {
+		var cols []models.Column
+		for _, name := range names {
+			cols = append(cols, models.Column{Name: name})
+		}
+		return cols
+	}
+	generated := models.Column{Name: "total", GenerationExpression: "`price` * `qty`", GeneratedStorage: "VIRTUAL"}
+
+	tests := []struct {
+		name     string
+		source   []models.Column
+		target   []models.Column
+		expected []string
+		ok       bool
+	}{
+		{"same columns", columns("id", "price", "qty"), columns("id", "qty", "price"), []string{"id", "price", "qty"}, true},
+		{"generated columns excluded", append(columns("id", "price", "qty"), generated), columns("id", "price", "qty"), []string{"id", "price", "qty"}, true},
+		{"column added in this run", columns("id", "price", "qty", "note"), columns("id", "price", "qty"), nil, false},
+		{"column only in target", columns("id", "price"), columns("id", "price", "legacy"), nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := checksumColumns(tt.source, tt.target)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v (%v), got %v (%v)", tt.name, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package sync
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/yuhuo/sync-db/config"
 	"github.com/yuhuo/sync-db/database"
 	"github.com/yuhuo/sync-db/models"
 )
@@ -14,24 +17,45 @@ type Comparator struct {
 	targetQueryHelper *database.QueryHelper
 	sourceConn        *database.Connection
 	targetConn        *database.Connection
+	options           config.CompareConfig
+	renames           config.RenameConfig
+	orphanTables      config.OrphanTableConfig
+	normalizer        schemaNormalizer // 按两边的服务端版本规范化元数据
 }
 
 // NewComparator 创建比较器
-func NewComparator(sourceConn, targetConn *database.Connection) *Comparator {
+func NewComparator(sourceConn, targetConn *database.Connection, options config.CompareConfig) *Comparator {
 	return &Comparator{
-		sourceQueryHelper: database.NewQueryHelper(sourceConn),
-		targetQueryHelper: database.NewQueryHelper(targetConn),
+		sourceQueryHelper: database.NewQueryHelper(sourceConn).WithPartitions(options.Partitions),
+		targetQueryHelper: database.NewQueryHelper(targetConn).WithPartitions(options.Partitions),
 		sourceConn:        sourceConn,
 		targetConn:        targetConn,
+		options:           options,
+		normalizer:        newSchemaNormalizer(sourceConn.Version(), targetConn.Version()),
 	}
 }
 
+// WithRenames 设置显式声明的改名，声明的改名直接视为已确认
+func (c *Comparator) WithRenames(renames config.RenameConfig) *Comparator {
+	c.renames = renames
+	return c
+}
+
+// WithOrphanTables 设置目标库独有的表的处理策略，未设置时不报告这些表
+func (c *Comparator) WithOrphanTables(orphanTables config.OrphanTableConfig) *Comparator {
+	c.orphanTables = orphanTables
+	return c
+}
+
 // CompareDifferences 比对源库和目标库的所有差异
 func (c *Comparator) CompareDifferences(syncDataTables []string) (*models.SyncDifference, error) {
 	diff := &models.SyncDifference{
 		StructureDifferences: []models.StructureDifference{},
 		DataDifferences:      make(map[string]models.DataDifference),
 		ViewDifferences:      []models.ViewDifference{},
+		TriggerDifferences:   []models.TriggerDifference{},
+		RoutineDifferences:   []models.RoutineDifference{},
+		EventDifferences:     []models.EventDifference{},
 	}
 
 	// 获取源库和目标库的表列表
@@ -46,18 +70,21 @@ func (c *Comparator) CompareDifferences(syncDataTables []string) (*models.SyncDi
 	}
 
 	// 比对表结构
-	structDiffs, err := c.compareTableStructures(sourceTables, targetTables)
+	structDiffs, tableRenames, err := c.compareTableStructures(sourceTables, targetTables)
 	if err != nil {
 		return nil, err
 	}
 	diff.StructureDifferences = structDiffs
+	diff.TableRenames = tableRenames
+	diff.OrphanTables = c.findOrphanTables(sourceTables, targetTables, tableRenames)
 
 	// 比对表数据（仅限配置的表）
-	dataDiffs, err := c.compareTableData(sourceTables, syncDataTables)
+	dataDiffs, skippedTables, err := c.compareTableData(sourceTables, targetTables, syncDataTables)
 	if err != nil {
 		return nil, err
 	}
 	diff.DataDifferences = dataDiffs
+	diff.SkippedDataTables = skippedTables
 
 	// 比对视图
 	viewDiffs, err := c.compareViews()
@@ -66,11 +93,33 @@ func (c *Comparator) CompareDifferences(syncDataTables []string) (*models.SyncDi
 	}
 	diff.ViewDifferences = viewDiffs
 
+	// 比对触发器
+	triggerDiffs, err := c.compareTriggers()
+	if err != nil {
+		return nil, err
+	}
+	diff.TriggerDifferences = triggerDiffs
+
+	// 比对存储过程和函数
+	routineDiffs, skippedRoutines, err := c.compareRoutines()
+	if err != nil {
+		return nil, err
+	}
+	diff.RoutineDifferences = routineDiffs
+	diff.SkippedRoutines = skippedRoutines
+
+	// 比对定时事件
+	eventDiffs, err := c.compareEvents()
+	if err != nil {
+		return nil, err
+	}
+	diff.EventDifferences = eventDiffs
+
 	return diff, nil
 }
 
-// compareTableStructures 比对表结构差异
-func (c *Comparator) compareTableStructures(sourceTables, targetTables []string) ([]models.StructureDifference, error) {
+// compareTableStructures 比对表结构差异，同时识别表改名
+func (c *Comparator) compareTableStructures(sourceTables, targetTables []string) ([]models.StructureDifference, []models.TableRename, error) {
 	var structDiffs []models.StructureDifference
 
 	sourceTableMap := make(map[string]bool)
@@ -83,47 +132,393 @@ func (c *Comparator) compareTableStructures(sourceTables, targetTables []string)
 		targetTableMap[t] = true
 	}
 
+	// 声明的表改名：旧表只在目标库、新表只在源库时生效
+	renamedFrom := make(map[string]string) // 新表名 → 旧表名
+	for oldName, newName := range c.renames.Tables {
+		if targetTableMap[oldName] && !sourceTableMap[oldName] && sourceTableMap[newName] && !targetTableMap[newName] {
+			renamedFrom[newName] = oldName
+		}
+	}
+
+	var renames []models.TableRename
+
 	// 比对源库的所有表
 	for _, tableName := range sourceTables {
 		sourceDef, err := c.sourceQueryHelper.GetTableDefinition(tableName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get source table definition: %w", err)
+			return nil, nil, fmt.Errorf("failed to get source table definition: %w", err)
 		}
 
-		diff := models.StructureDifference{
-			TableName: tableName,
+		if oldName, renamed := renamedFrom[tableName]; renamed {
+			// 声明的改名：与目标库中的旧表比对，其余差异在改名之后执行
+			targetDef, err := c.targetQueryHelper.GetTableDefinition(oldName)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get target table definition: %w", err)
+			}
+			diff := c.compareTableDefinitions(tableName, oldName, sourceDef, targetDef)
+			diff.RenamedFrom = oldName
+			structDiffs = append(structDiffs, diff)
+			renames = append(renames, models.TableRename{OldName: oldName, NewName: tableName, Confirmed: true})
+			continue
 		}
 
 		if !targetTableMap[tableName] {
 			// 目标库中不存在这个表，这是一个新表
-			diff.IsNewTable = true
-			diff.TableDefinition = sourceDef
-			diff.ColumnsAdded = sourceDef.Columns
-			diff.IndexesAdded = sourceDef.Indexes
-		} else {
-			targetDef, err := c.targetQueryHelper.GetTableDefinition(tableName)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get target table definition: %w", err)
+			structDiffs = append(structDiffs, models.StructureDifference{
+				TableName:       tableName,
+				IsNewTable:      true,
+				TableDefinition: sourceDef,
+				ColumnsAdded:    sourceDef.Columns,
+				IndexesAdded:    sourceDef.Indexes,
+			})
+			continue
+		}
+
+		targetDef, err := c.targetQueryHelper.GetTableDefinition(tableName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get target table definition: %w", err)
+		}
+		structDiffs = append(structDiffs, c.compareTableDefinitions(tableName, tableName, sourceDef, targetDef))
+	}
+
+	// 推测的表改名：新表与目标库独有的表结构完全相同
+	detected, err := c.detectTableRenames(structDiffs, sourceTableMap, renamedFrom, targetTables)
+	if err != nil {
+		return nil, nil, err
+	}
+	renames = append(renames, detected...)
+
+	return structDiffs, renames, nil
+}
+
+// archivedTablePattern 匹配按 archive 策略归档后的表名
+var archivedTablePattern = regexp.MustCompile(`_archived_\d{14}$`)
+
+// findOrphanTables 找出只存在于目标库的表，已声明改名的旧表和已归档的表除外
+// 推测改名的旧表仍列为孤立表，确认改名后由 SyncDifference.ConfirmTableRename 移除
+func (c *Comparator) findOrphanTables(sourceTables, targetTables []string, renames []models.TableRename) []models.OrphanTable {
+	action := models.OrphanReport
+	switch c.orphanTables.Policy {
+	case config.OrphanPolicyReport:
+	case config.OrphanPolicyDrop:
+		action = models.OrphanDrop
+		if c.orphanTables.Archive {
+			action = models.OrphanArchive
+		}
+	default:
+		return nil
+	}
+
+	sourceTableMap := make(map[string]bool)
+	for _, t := range sourceTables {
+		sourceTableMap[t] = true
+	}
+	renamedTables := make(map[string]bool)
+	for _, rename := range renames {
+		if rename.Confirmed {
+			renamedTables[rename.OldName] = true
+		}
+	}
+
+	var orphans []models.OrphanTable
+	for _, tableName := range targetTables {
+		if archivedTablePattern.MatchString(tableName) {
+			continue // 已归档的表不再处理
+		}
+		if !sourceTableMap[tableName] && !renamedTables[tableName] {
+			orphans = append(orphans, models.OrphanTable{TableName: tableName, Action: action})
+		}
+	}
+	return orphans
+}
+
+// compareTableDefinitions 比对同一张表在源库和目标库中的定义，targetName 为目标库中的表名
+func (c *Comparator) compareTableDefinitions(tableName, targetName string, sourceDef, targetDef *models.TableDefinition) models.StructureDifference {
+	diff := models.StructureDifference{
+		TableName: tableName,
+	}
+
+	// 比对列、索引
+	colDiff, colMod := c.compareColumns(sourceDef.Columns, targetDef.Columns)
+	diff.ColumnsAdded = colDiff.added
+	diff.ColumnsDeleted = colDiff.deleted
+	diff.ColumnsModified = colMod
+	detectColumnRenames(c.normalizer, c.renames.Columns[tableName], sourceDef.Columns, targetDef.Columns, &diff)
+	diff.ColumnsReordered = compareColumnOrder(sourceDef.Columns, targetDef.Columns)
+	diff.ColumnOrder = getColumnNames(sourceDef.Columns)
+
+	indexDiff := c.compareIndexes(sourceDef.Indexes, targetDef.Indexes)
+	diff.IndexesAdded = indexDiff.added
+	diff.IndexesDeleted = indexDiff.deleted
+	diff.IndexesModified = indexDiff.modified
+	diff.IndexesRenamed = indexDiff.renamed
+
+	diff.ForeignKeysAdded, diff.ForeignKeysDeleted = compareForeignKeys(sourceDef.ForeignKeys, targetDef.ForeignKeys)
+	rebuildForeignKeysOnDroppedIndexes(&diff, targetDef)
+
+	diff.ChecksAdded, diff.ChecksDeleted = compareCheckConstraints(sourceDef.CheckConstraints, targetDef.CheckConstraints)
+	diff.CheckViolations = c.findCheckViolations(targetName, diff.ChecksAdded)
+
+	diff.OptionsModified = compareTableOptions(sourceDef, targetDef, c.options.CharsetDrift)
+	diff.PartitionChange = comparePartitioning(sourceDef.Partitioning, targetDef.Partitioning)
+	if c.options.CharsetDrift {
+		diff.CharsetChanges = compareColumnCharsets(sourceDef.Columns, targetDef.Columns, diff.ColumnsModified)
+	} else {
+		keepTargetCharsets(&diff)
+	}
+
+	return diff
+}
+
+// keepTargetCharsets 未开启 CharsetDrift 时，修改和改名的列保留目标库中的字符集和排序规则
+// 否则 MODIFY/CHANGE COLUMN 会按源库的定义顺带修改字符集
+func keepTargetCharsets(diff *models.StructureDifference) {
+	keep := func(newCol *models.Column, oldCol models.Column) {
+		if newCol.Collation != nil {
+			newCol.Charset, newCol.Collation = oldCol.Charset, oldCol.Collation
+		}
+	}
+	for i := range diff.ColumnsModified {
+		keep(&diff.ColumnsModified[i].NewColumn, diff.ColumnsModified[i].OldColumn)
+	}
+	for i := range diff.ColumnsRenamed {
+		keep(&diff.ColumnsRenamed[i].NewColumn, diff.ColumnsRenamed[i].OldColumn)
+	}
+}
+
+// compareColumnCharsets 找出两边都存在、其余定义相同但字符集或排序规则不同的列
+// 定义有其他变化的列已在修改列中按源库定义（含字符集）修改，不再重复列出
+func compareColumnCharsets(sourceColumns, targetColumns []models.Column, modified []models.ColumnModification) []models.ColumnCharsetChange {
+	isModified := make(map[string]bool)
+	for _, mod := range modified {
+		isModified[mod.ColumnName] = true
+	}
+	targetMap := make(map[string]models.Column)
+	for _, col := range targetColumns {
+		targetMap[col.Name] = col
+	}
+
+	var changes []models.ColumnCharsetChange
+	for _, sourceCol := range sourceColumns {
+		targetCol, exists := targetMap[sourceCol.Name]
+		if !exists || isModified[sourceCol.Name] || sourceCol.Collation == nil || targetCol.Collation == nil {
+			continue
+		}
+		if !sameCollation(*sourceCol.Collation, *targetCol.Collation) {
+			changes = append(changes, models.ColumnCharsetChange{
+				ColumnName:   sourceCol.Name,
+				OldCollation: *targetCol.Collation,
+				NewCollation: *sourceCol.Collation,
+				NewColumn:    sourceCol,
+			})
+		}
+	}
+	return changes
+}
+
+// comparePartitioning 比对分区定义，没有差异时返回 nil
+// 分区方式、表达式不同，或 HASH/KEY 分区的数量不同时整体重新分区
+// RANGE 分区：目标库开头多出的分区删除，跳过两边相同的分区后，剩余部分只有源库有则新增，只有目标库有则删除，否则重组
+// LIST 分区：按分区名比对，取值不同的分区一起重组，避免取值在分区之间移动时互相冲突
+func comparePartitioning(source, target *models.Partitioning) *models.PartitionDifference {
+	if source == nil && target == nil {
+		return nil
+	}
+
+	diff := &models.PartitionDifference{OldPartitioning: target, NewPartitioning: source}
+	if source == nil || target == nil || source.Method != target.Method ||
+		normalizeExpression(source.Expression) != normalizeExpression(target.Expression) {
+		diff.Repartition = true
+		return diff
+	}
+
+	switch {
+	case source.IsRange():
+		sourceNames := make(map[string]bool)
+		for _, part := range source.Partitions {
+			sourceNames[part.Name] = true
+		}
+
+		i, j := 0, 0
+		for i < len(target.Partitions) && !sourceNames[target.Partitions[i].Name] {
+			diff.PartitionsDropped = append(diff.PartitionsDropped, target.Partitions[i])
+			i++
+		}
+		for i < len(target.Partitions) && j < len(source.Partitions) && target.Partitions[i] == source.Partitions[j] {
+			i++
+			j++
+		}
+
+		targetRest, sourceRest := target.Partitions[i:], source.Partitions[j:]
+		switch {
+		case len(targetRest) == 0:
+			diff.PartitionsAdded = sourceRest
+		case len(sourceRest) == 0:
+			diff.PartitionsDropped = append(diff.PartitionsDropped, targetRest...)
+		default:
+			reorganize := models.PartitionReorganize{Into: sourceRest}
+			for _, part := range targetRest {
+				reorganize.From = append(reorganize.From, part.Name)
 			}
+			diff.PartitionsReorganized = append(diff.PartitionsReorganized, reorganize)
+		}
 
-			// 比对列、索引
-			colDiff, colMod := c.compareColumns(sourceDef.Columns, targetDef.Columns)
-			diff.ColumnsAdded = colDiff.added
-			diff.ColumnsDeleted = colDiff.deleted
-			diff.ColumnsModified = colMod
+	case source.IsList():
+		targetParts := make(map[string]models.Partition)
+		for _, part := range target.Partitions {
+			targetParts[part.Name] = part
+		}
 
-			indexDiff := c.compareIndexes(sourceDef.Indexes, targetDef.Indexes)
-			diff.IndexesAdded = indexDiff.added
-			diff.IndexesDeleted = indexDiff.deleted
+		var reorganize models.PartitionReorganize
+		sourceNames := make(map[string]bool)
+		for _, part := range source.Partitions {
+			sourceNames[part.Name] = true
+			targetPart, exists := targetParts[part.Name]
+			if !exists {
+				diff.PartitionsAdded = append(diff.PartitionsAdded, part)
+			} else if normalizeWhitespace(targetPart.Description) != normalizeWhitespace(part.Description) {
+				reorganize.From = append(reorganize.From, part.Name)
+				reorganize.Into = append(reorganize.Into, part)
+			}
+		}
+		if len(reorganize.From) > 0 {
+			diff.PartitionsReorganized = append(diff.PartitionsReorganized, reorganize)
+		}
+		for _, part := range target.Partitions {
+			if !sourceNames[part.Name] {
+				diff.PartitionsDropped = append(diff.PartitionsDropped, part)
+			}
 		}
 
-		structDiffs = append(structDiffs, diff)
+	default:
+		diff.Repartition = len(source.Partitions) != len(target.Partitions)
 	}
 
-	// 比对目标库中源库不存在的表（这些表在目标库中不应该被删除，暂时不处理）
-	// 但我们需要记录这些表的存在，后续可能需要删除
+	if diff.ChangeCount() == 0 {
+		return nil
+	}
+	return diff
+}
+
+// compareTableOptions 比对表选项，compareCharset 为 true（开启 CharsetDrift）时比对表的默认字符集
+// 默认字符集变化时，若源表所有字符列都使用表的排序规则则转换全部列（CONVERT TO，会重建表），否则只修改表的默认字符集
+// AUTO_INCREMENT 只在目标库小于源库时调高，不会调低
+func compareTableOptions(sourceDef, targetDef *models.TableDefinition, compareCharset bool) []models.TableOptionChange {
+	var changes []models.TableOptionChange
+	add := func(option, oldValue, newValue string, rebuild bool) {
+		changes = append(changes, models.TableOptionChange{Option: option, OldValue: oldValue, NewValue: newValue, Rebuild: rebuild})
+	}
 
-	return structDiffs, nil
+	if !strings.EqualFold(sourceDef.Engine, targetDef.Engine) {
+		add("ENGINE", targetDef.Engine, sourceDef.Engine, true)
+	}
+	if !strings.EqualFold(sourceDef.RowFormat, targetDef.RowFormat) {
+		add("ROW_FORMAT", targetDef.RowFormat, sourceDef.RowFormat, true)
+	}
+	if sourceDef.KeyBlockSize != targetDef.KeyBlockSize {
+		add("KEY_BLOCK_SIZE", fmt.Sprint(targetDef.KeyBlockSize), fmt.Sprint(sourceDef.KeyBlockSize), true)
+	}
+	if !strings.EqualFold(sourceDef.Compression, targetDef.Compression) {
+		add("COMPRESSION", targetDef.Compression, sourceDef.Compression, false)
+	}
+	if sourceDef.Comment != targetDef.Comment {
+		add("COMMENT", targetDef.Comment, sourceDef.Comment, false)
+	}
+
+	if compareCharset && sourceDef.Collation != nil && targetDef.Collation != nil && !sameCollation(*sourceDef.Collation, *targetDef.Collation) {
+		convert := true
+		for _, col := range sourceDef.Columns {
+			if col.Collation != nil && !sameCollation(*col.Collation, *sourceDef.Collation) {
+				convert = false
+				break
+			}
+		}
+		if convert {
+			add("CONVERT TO CHARACTER SET", *targetDef.Collation, *sourceDef.Collation, true)
+		} else {
+			add("DEFAULT CHARACTER SET", *targetDef.Collation, *sourceDef.Collation, false)
+		}
+	}
+
+	if sourceDef.AutoIncrement > targetDef.AutoIncrement && targetDef.AutoIncrement > 0 {
+		add("AUTO_INCREMENT", fmt.Sprint(targetDef.AutoIncrement), fmt.Sprint(sourceDef.AutoIncrement), false)
+	}
+
+	return changes
+}
+
+// detectTableRenames 在新表和目标库独有的表之间寻找结构完全相同的一对，作为需要用户确认的改名
+// 新表和旧表必须一一对应，存在多个候选时不推测
+func (c *Comparator) detectTableRenames(structDiffs []models.StructureDifference, sourceTableMap map[string]bool, renamedFrom map[string]string, targetTables []string) ([]models.TableRename, error) {
+	declaredOld := make(map[string]bool)
+	for _, oldName := range renamedFrom {
+		declaredOld[oldName] = true
+	}
+
+	var orphanDefs []*models.TableDefinition
+	for _, tableName := range targetTables {
+		if sourceTableMap[tableName] || declaredOld[tableName] {
+			continue
+		}
+		targetDef, err := c.targetQueryHelper.GetTableDefinition(tableName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get target table definition: %w", err)
+		}
+		orphanDefs = append(orphanDefs, targetDef)
+	}
+
+	return c.matchTableRenames(structDiffs, orphanDefs), nil
+}
+
+// matchTableRenames 在新表和目标库独有的表之间匹配结构相同的一对
+// 表选项和分区不影响匹配，确认改名后作为改名之后的差异处理
+func (c *Comparator) matchTableRenames(structDiffs []models.StructureDifference, orphanDefs []*models.TableDefinition) []models.TableRename {
+	orphanMap := make(map[string]*models.TableDefinition)
+	for _, orphanDef := range orphanDefs {
+		orphanMap[orphanDef.TableName] = orphanDef
+	}
+
+	candidates := make(map[string][]string) // 新表名 → 候选旧表名
+	candidateCount := make(map[string]int)  // 旧表名 → 候选新表数
+	for _, structDiff := range structDiffs {
+		if !structDiff.IsNewTable {
+			continue
+		}
+		for _, orphanDef := range orphanDefs {
+			if c.sameStructure(structDiff.TableDefinition, orphanDef) {
+				candidates[structDiff.TableName] = append(candidates[structDiff.TableName], orphanDef.TableName)
+				candidateCount[orphanDef.TableName]++
+			}
+		}
+	}
+
+	var renames []models.TableRename
+	for _, structDiff := range structDiffs {
+		oldNames := candidates[structDiff.TableName]
+		if len(oldNames) == 1 && candidateCount[oldNames[0]] == 1 {
+			remaining := c.compareTableDefinitions(structDiff.TableName, oldNames[0], structDiff.TableDefinition, orphanMap[oldNames[0]])
+			renames = append(renames, models.TableRename{OldName: oldNames[0], NewName: structDiff.TableName, Difference: &remaining})
+		}
+	}
+	return renames
+}
+
+// sameStructure 判断两张表的列（含顺序）、索引、外键和 CHECK 约束是否完全相同
+func (c *Comparator) sameStructure(def1, def2 *models.TableDefinition) bool {
+	colDiff, colMod := c.compareColumns(def1.Columns, def2.Columns)
+	if len(colDiff.added) > 0 || len(colDiff.deleted) > 0 || len(colMod) > 0 ||
+		len(compareColumnOrder(def1.Columns, def2.Columns)) > 0 {
+		return false
+	}
+
+	indexDiff := c.compareIndexes(def1.Indexes, def2.Indexes)
+	if len(indexDiff.added) > 0 || len(indexDiff.deleted) > 0 || len(indexDiff.modified) > 0 || len(indexDiff.renamed) > 0 {
+		return false
+	}
+
+	fkAdded, fkDeleted := compareForeignKeys(def1.ForeignKeys, def2.ForeignKeys)
+	checksAdded, checksDeleted := compareCheckConstraints(def1.CheckConstraints, def2.CheckConstraints)
+	return len(fkAdded) == 0 && len(fkDeleted) == 0 && len(checksAdded) == 0 && len(checksDeleted) == 0
 }
 
 // compareColumns 比对列定义
@@ -151,11 +546,12 @@ func (c *Comparator) compareColumns(sourceColumns, targetColumns []models.Column
 			added = append(added, sourceCol)
 		} else {
 			targetCol := targetColMap[sourceCol.Name]
-			if !columnsEqual(sourceCol, targetCol) {
+			if !columnsEqual(c.normalizer.column(sourceCol), c.normalizer.column(targetCol)) {
 				modifications = append(modifications, models.ColumnModification{
-					ColumnName: sourceCol.Name,
-					OldColumn:  targetCol,
-					NewColumn:  sourceCol,
+					ColumnName:    sourceCol.Name,
+					OldColumn:     targetCol,
+					NewColumn:     sourceCol,
+					Compatibility: classifyColumnChange(targetCol, sourceCol),
 				})
 			}
 		}
@@ -174,6 +570,150 @@ func (c *Comparator) compareColumns(sourceColumns, targetColumns []models.Column
 	}{added, deleted}, modifications
 }
 
+// detectColumnRenames 识别列改名
+// 配置中声明的改名直接确认；其余删除列和新增列中定义相同（忽略列名，与 compareColumns 一样按规范化后的定义比较）且位置相符的一对视为推测的改名，需要用户确认
+// 结果写入 diff.ColumnsRenamed，已确认改名对应的列从 diff 的新增和删除中移除
+func detectColumnRenames(n schemaNormalizer, declared map[string]string, sourceColumns, targetColumns []models.Column, diff *models.StructureDifference) {
+	sourceColMap := make(map[string]models.Column)
+	for _, col := range sourceColumns {
+		sourceColMap[col.Name] = col
+	}
+	targetColMap := make(map[string]models.Column)
+	for _, col := range targetColumns {
+		targetColMap[col.Name] = col
+	}
+
+	isDeleted := make(map[string]bool)
+	for _, name := range diff.ColumnsDeleted {
+		isDeleted[name] = true
+	}
+	isAdded := make(map[string]bool)
+	for _, col := range diff.ColumnsAdded {
+		isAdded[col.Name] = true
+	}
+
+	var renames []models.ColumnRename
+
+	// 声明的改名：旧列只在目标库、新列只在源库时生效
+	for oldName, newName := range declared {
+		if !isDeleted[oldName] || !isAdded[newName] {
+			continue
+		}
+		renames = append(renames, models.ColumnRename{
+			OldName:   oldName,
+			NewName:   newName,
+			OldColumn: targetColMap[oldName],
+			NewColumn: sourceColMap[newName],
+		})
+		isDeleted[oldName] = false
+		isAdded[newName] = false
+	}
+	sort.Slice(renames, func(i, j int) bool { return renames[i].NewColumn.Position < renames[j].NewColumn.Position })
+	declaredCount := len(renames)
+
+	// 推测的改名：删除列和新增列一一对应才采用，存在多个候选时不推测
+	candidates := make(map[string][]string) // 新列名 → 候选旧列名
+	candidateCount := make(map[string]int)  // 旧列名 → 候选新列数
+	for _, sourceCol := range sourceColumns {
+		if !isAdded[sourceCol.Name] {
+			continue
+		}
+		for _, targetCol := range targetColumns {
+			if !isDeleted[targetCol.Name] {
+				continue
+			}
+			renamed := targetCol
+			renamed.Name = sourceCol.Name
+			if columnsEqual(n.column(sourceCol), n.column(renamed)) && positionCompatible(sourceCol, targetCol, sourceColumns, targetColumns) {
+				candidates[sourceCol.Name] = append(candidates[sourceCol.Name], targetCol.Name)
+				candidateCount[targetCol.Name]++
+			}
+		}
+	}
+	for _, sourceCol := range sourceColumns {
+		oldNames := candidates[sourceCol.Name]
+		if len(oldNames) != 1 || candidateCount[oldNames[0]] != 1 {
+			continue
+		}
+		renames = append(renames, models.ColumnRename{
+			OldName:   oldNames[0],
+			NewName:   sourceCol.Name,
+			OldColumn: targetColMap[oldNames[0]],
+			NewColumn: sourceCol,
+		})
+	}
+
+	// 声明的改名排在前面，直接确认
+	diff.ColumnsRenamed = renames
+	for i := 0; i < declaredCount; i++ {
+		diff.ConfirmColumnRename(i)
+	}
+}
+
+// positionCompatible 判断改名前后的列位置是否相符：序号相同，或前一列的列名相同
+func positionCompatible(sourceCol, targetCol models.Column, sourceColumns, targetColumns []models.Column) bool {
+	if sourceCol.Position == targetCol.Position {
+		return true
+	}
+	previousName := func(columns []models.Column, position int) string {
+		for _, col := range columns {
+			if col.Position == position-1 {
+				return col.Name
+			}
+		}
+		return ""
+	}
+	return previousName(sourceColumns, sourceCol.Position) == previousName(targetColumns, targetCol.Position)
+}
+
+// compareColumnOrder 找出两边都存在但相对顺序不同的列，返回目标库中需要移动的列（按源库顺序）
+// 保留按源库顺序排列的最长递增子序列不动，其余的列需要移动，使移动的列数最少
+func compareColumnOrder(sourceColumns, targetColumns []models.Column) []models.Column {
+	sourceIndex := make(map[string]int)
+	for i, col := range sourceColumns {
+		sourceIndex[col.Name] = i
+	}
+
+	// 目标库中两边都存在的列，按目标库顺序排列
+	var common []models.Column
+	for _, col := range targetColumns {
+		if _, exists := sourceIndex[col.Name]; exists {
+			common = append(common, col)
+		}
+	}
+
+	// 求源库位置的最长递增子序列
+	n := len(common)
+	length := make([]int, n)
+	prev := make([]int, n)
+	best := -1
+	for i := 0; i < n; i++ {
+		length[i], prev[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if sourceIndex[common[j].Name] < sourceIndex[common[i].Name] && length[j]+1 > length[i] {
+				length[i], prev[i] = length[j]+1, j
+			}
+		}
+		if best == -1 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	stable := make(map[string]bool)
+	for i := best; i >= 0; i = prev[i] {
+		stable[common[i].Name] = true
+	}
+
+	var moved []models.Column
+	for _, col := range common {
+		if !stable[col.Name] {
+			moved = append(moved, col)
+		}
+	}
+	sort.Slice(moved, func(i, j int) bool { return sourceIndex[moved[i].Name] < sourceIndex[moved[j].Name] })
+	return moved
+}
+
 // columnsEqual 判断两个列是否相等
 // 注意：忽略字符集和排序规则的差异，因为这通常不需要修改列定义
 func columnsEqual(col1, col2 models.Column) bool {
@@ -182,7 +722,7 @@ func columnsEqual(col1, col2 models.Column) bool {
 		return false
 	}
 
-	// 规范化类型进行比对（忽略长度括号）
+	// 规范化类型进行比对（长度、精度等参数需完全一致，忽略整数显示宽度）
 	type1 := normalizeType(col1.Type)
 	type2 := normalizeType(col2.Type)
 	if type1 != type2 {
@@ -193,20 +733,29 @@ func columnsEqual(col1, col2 models.Column) bool {
 		return false
 	}
 
-	// 比对默认值
-	if (col1.DefaultValue == nil) != (col2.DefaultValue == nil) {
+	// 比对默认值（字面量与表达式视为不同）和 ON UPDATE
+	if (col1.DefaultValue == nil) != (col2.DefaultValue == nil) || col1.DefaultIsExpression != col2.DefaultIsExpression {
 		return false
 	}
 	if col1.DefaultValue != nil && col2.DefaultValue != nil {
-		if *col1.DefaultValue != *col2.DefaultValue {
+		if normalizeDefault(col1) != normalizeDefault(col2) {
 			return false
 		}
 	}
+	if models.NormalizeCurrentTimestamp(col1.OnUpdate) != models.NormalizeCurrentTimestamp(col2.OnUpdate) {
+		return false
+	}
 
 	if col1.IsAutoIncrement != col2.IsAutoIncrement {
 		return false
 	}
 
+	// 比对生成列的表达式和存储方式
+	if col1.GeneratedStorage != col2.GeneratedStorage ||
+		normalizeExpression(col1.GenerationExpression) != normalizeExpression(col2.GenerationExpression) {
+		return false
+	}
+
 	// 比对列注释
 	if (col1.Comment == nil) != (col2.Comment == nil) {
 		return false
@@ -217,39 +766,23 @@ func columnsEqual(col1, col2 models.Column) bool {
 		}
 	}
 
-	// 注意：NOT 比对 Charset 和 Collation，因为这些差异通常不需要修改列
-	// 如果用户需要修改字符集，应该单独使用 ALTER TABLE ... CONVERT TO CHARSET
+	// 注意：NOT 比对 Charset 和 Collation，开启 CharsetDrift 时由 compareColumnCharsets 单独比对
 
 	return true
 }
 
-// normalizeType 规范化列类型，忽略长度参数但保留修饰符
-func normalizeType(typeStr string) string {
-	// 转换为大起进行比对
-	typeStr = strings.ToUpper(typeStr)
-
-	// 移除括号内的内容，但保留括号后面的修饰符
-	// 例如：VARCHAR(255) → VARCHAR，TINYINT(1) UNSIGNED → TINYINT UNSIGNED
-	idx := strings.Index(typeStr, "(")
-	endIdx := strings.Index(typeStr, ")")
-	if idx > 0 && endIdx > idx {
-		// 去掉括号及其内容，但保留后面的部分（如 UNSIGNED）
-		typeStr = typeStr[:idx] + typeStr[endIdx+1:]
-	}
-
-	// 清除多余的空格
-	typeStr = strings.TrimSpace(typeStr)
-	// 将多个空格替换为单个空格
-	typeStr = strings.Join(strings.Fields(typeStr), " ")
-
-	return typeStr
+// indexDiffResult 表示索引比对的结果
+type indexDiffResult struct {
+	added    []models.Index
+	deleted  []models.Index
+	modified []models.IndexModification
+	renamed  []models.IndexRename
 }
 
 // compareIndexes 比对索引定义
-func (c *Comparator) compareIndexes(sourceIndexes, targetIndexes []models.Index) struct {
-	added   []models.Index
-	deleted []models.Index
-} {
+// 同名索引比对完整定义（类型、列、前缀长度、排序方向等）；
+// 仅存在于一侧的索引若定义完全相同，视为改名而不是删除后重建
+func (c *Comparator) compareIndexes(sourceIndexes, targetIndexes []models.Index) indexDiffResult {
 	sourceIndexMap := make(map[string]models.Index)
 	for _, idx := range sourceIndexes {
 		sourceIndexMap[idx.Name] = idx
@@ -260,127 +793,572 @@ func (c *Comparator) compareIndexes(sourceIndexes, targetIndexes []models.Index)
 		targetIndexMap[idx.Name] = idx
 	}
 
+	var result indexDiffResult
 	var added, deleted []models.Index
 
-	for name, sourceIdx := range sourceIndexMap {
-		if _, exists := targetIndexMap[name]; !exists {
+	for _, sourceIdx := range sourceIndexes {
+		targetIdx, exists := targetIndexMap[sourceIdx.Name]
+		if !exists {
 			added = append(added, sourceIdx)
+		} else if indexSignature(c.normalizer.index(sourceIdx)) != indexSignature(c.normalizer.index(targetIdx)) {
+			result.modified = append(result.modified, models.IndexModification{
+				IndexName: sourceIdx.Name,
+				OldIndex:  targetIdx,
+				NewIndex:  sourceIdx,
+			})
 		}
 	}
 
-	for name, targetIdx := range targetIndexMap {
-		if _, exists := sourceIndexMap[name]; !exists {
+	for _, targetIdx := range targetIndexes {
+		if _, exists := sourceIndexMap[targetIdx.Name]; !exists {
 			deleted = append(deleted, targetIdx)
 		}
 	}
 
-	return struct {
-		added   []models.Index
-		deleted []models.Index
-	}{added, deleted}
+	// 在新增和删除的索引之间按定义配对，识别改名
+	renamedFrom := make(map[string]bool)
+	for _, sourceIdx := range added {
+		renamed := false
+		if sourceIdx.Type != "PRIMARY" {
+			for _, targetIdx := range deleted {
+				if !renamedFrom[targetIdx.Name] && indexSignature(c.normalizer.index(sourceIdx)) == indexSignature(c.normalizer.index(targetIdx)) {
+					renamedFrom[targetIdx.Name] = true
+					result.renamed = append(result.renamed, models.IndexRename{
+						OldName: targetIdx.Name,
+						NewName: sourceIdx.Name,
+						Index:   sourceIdx,
+					})
+					renamed = true
+					break
+				}
+			}
+		}
+		if !renamed {
+			result.added = append(result.added, sourceIdx)
+		}
+	}
+
+	for _, targetIdx := range deleted {
+		if !renamedFrom[targetIdx.Name] {
+			result.deleted = append(result.deleted, targetIdx)
+		}
+	}
+
+	return result
+}
+
+// indexSignature 返回不含索引名的索引定义，用于判断两个索引是否等价
+func indexSignature(idx models.Index) string {
+	idx.Name = ""
+	return buildIndexDefinition(idx)
+}
+
+// compareForeignKeys 比对外键定义，定义变化（列、被引用表、ON DELETE/ON UPDATE 规则）的外键需要先删除再新增
+func compareForeignKeys(sourceFKs, targetFKs []models.ForeignKey) (added, deleted []models.ForeignKey) {
+	targetFKMap := make(map[string]models.ForeignKey)
+	for _, fk := range targetFKs {
+		targetFKMap[fk.Name] = fk
+	}
+
+	sourceFKMap := make(map[string]models.ForeignKey)
+	for _, fk := range sourceFKs {
+		sourceFKMap[fk.Name] = fk
+	}
+
+	for _, sourceFK := range sourceFKs {
+		targetFK, exists := targetFKMap[sourceFK.Name]
+		if !exists {
+			added = append(added, sourceFK)
+		} else if buildForeignKeyDefinition(sourceFK) != buildForeignKeyDefinition(targetFK) {
+			deleted = append(deleted, targetFK)
+			added = append(added, sourceFK)
+		}
+	}
+
+	for _, targetFK := range targetFKs {
+		if _, exists := sourceFKMap[targetFK.Name]; !exists {
+			deleted = append(deleted, targetFK)
+		}
+	}
+
+	return added, deleted
+}
+
+// rebuildForeignKeysOnDroppedIndexes 删除的索引是未变化的外键唯一可用的索引时，目标库会拒绝删除该索引
+// 这类外键在删除索引之前删除，在索引就绪之后重新添加（没有可用的索引时由数据库自动创建）
+func rebuildForeignKeysOnDroppedIndexes(diff *models.StructureDifference, targetDef *models.TableDefinition) {
+	dropped := make(map[string]bool)
+	for _, idx := range diff.IndexesDeleted {
+		dropped[idx.Name] = true
+	}
+	// 修改的索引在同一条语句中删除后重建，新定义仍可用于外键时不影响外键
+	replaced := make(map[string]models.Index)
+	for _, mod := range diff.IndexesModified {
+		replaced[mod.IndexName] = mod.NewIndex
+	}
+	if len(dropped) == 0 && len(replaced) == 0 {
+		return
+	}
+	changed := make(map[string]bool)
+	for _, fk := range diff.ForeignKeysDeleted {
+		changed[fk.Name] = true
+	}
+
+	for _, fk := range targetDef.ForeignKeys {
+		if changed[fk.Name] {
+			continue
+		}
+		needsDropped, hasRemaining := false, false
+		for _, idx := range targetDef.Indexes {
+			if !indexSupportsForeignKey(idx, fk) {
+				continue
+			}
+			newIdx, isReplaced := replaced[idx.Name]
+			if dropped[idx.Name] || (isReplaced && !indexSupportsForeignKey(newIdx, fk)) {
+				needsDropped = true
+			} else {
+				hasRemaining = true
+			}
+		}
+		if needsDropped && !hasRemaining {
+			diff.ForeignKeysDeleted = append(diff.ForeignKeysDeleted, fk)
+			diff.ForeignKeysAdded = append(diff.ForeignKeysAdded, fk)
+		}
+	}
+}
+
+// indexSupportsForeignKey 判断索引能否用于外键：外键列按顺序是索引最左边的列（不能是前缀索引）
+func indexSupportsForeignKey(idx models.Index, fk models.ForeignKey) bool {
+	if len(idx.Parts) < len(fk.Columns) {
+		return false
+	}
+	for i, col := range fk.Columns {
+		part := idx.Parts[i]
+		if part.Expression != "" || part.SubPart > 0 || !strings.EqualFold(part.Column, col) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareCheckConstraints 比对 CHECK 约束，表达式或 ENFORCED 变化的约束需要先删除再新增
+func compareCheckConstraints(sourceChecks, targetChecks []models.CheckConstraint) (added, deleted []models.CheckConstraint) {
+	targetCheckMap := make(map[string]models.CheckConstraint)
+	for _, check := range targetChecks {
+		targetCheckMap[check.Name] = check
+	}
+
+	sourceCheckMap := make(map[string]models.CheckConstraint)
+	for _, check := range sourceChecks {
+		sourceCheckMap[check.Name] = check
+	}
+
+	for _, sourceCheck := range sourceChecks {
+		targetCheck, exists := targetCheckMap[sourceCheck.Name]
+		if !exists {
+			added = append(added, sourceCheck)
+		} else if normalizeExpression(sourceCheck.Expression) != normalizeExpression(targetCheck.Expression) ||
+			sourceCheck.Enforced != targetCheck.Enforced {
+			deleted = append(deleted, targetCheck)
+			added = append(added, sourceCheck)
+		}
+	}
+
+	for _, targetCheck := range targetChecks {
+		if _, exists := sourceCheckMap[targetCheck.Name]; !exists {
+			deleted = append(deleted, targetCheck)
+		}
+	}
+
+	return added, deleted
+}
+
+// normalizeDefault 返回用于比较的默认值，表达式默认值统一空白、外层括号和 CURRENT_TIMESTAMP 的写法
+func normalizeDefault(col models.Column) string {
+	if !col.DefaultIsExpression {
+		return *col.DefaultValue
+	}
+	return models.NormalizeCurrentTimestamp(normalizeExpression(*col.DefaultValue))
+}
+
+// normalizeExpression 规范化 CHECK 约束、生成列等的表达式：合并空白并去除包裹整个表达式的括号
+func normalizeExpression(expr string) string {
+	expr = normalizeWhitespace(expr)
+	for len(expr) >= 2 && expr[0] == '(' && matchingParen(expr) == len(expr)-1 {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// matchingParen 返回与 expr[0] 处左括号匹配的右括号位置，忽略引号内的括号；没有匹配时返回 -1
+func matchingParen(expr string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// checkViolationSampleSize 每个约束最多展示的违反约束的行数
+const checkViolationSampleSize = 10
+
+// findCheckViolations 在目标库中预先检查新增的 CHECK 约束，找出现有数据不满足的约束
+// 表达式无法在目标库执行（例如引用了尚未新增的列，或两边的语法不同）时无法确认约束能否添加，同样记录下来
+func (c *Comparator) findCheckViolations(tableName string, checks []models.CheckConstraint) []models.CheckViolation {
+	var violations []models.CheckViolation
+	for _, check := range checks {
+		if !check.Enforced {
+			continue // NOT ENFORCED 的约束不检查现有数据
+		}
+		count, samples, err := c.targetQueryHelper.FindCheckViolations(tableName, check.Expression, checkViolationSampleSize)
+		if violation, ok := checkViolation(check, count, samples, err); ok {
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
+
+// checkViolation 根据检查结果生成约束的违反记录，约束已验证且没有违反的行时返回 false
+func checkViolation(check models.CheckConstraint, count int64, samples []map[string]interface{}, err error) (models.CheckViolation, bool) {
+	if err != nil {
+		return models.CheckViolation{Constraint: check, Error: err.Error()}, true
+	}
+	if count == 0 {
+		return models.CheckViolation{}, false
+	}
+	return models.CheckViolation{Constraint: check, RowCount: count, SampleRows: samples}, true
 }
 
 // compareTableData 比对表数据差异
-func (c *Comparator) compareTableData(sourceTables []string, syncDataTables []string) (map[string]models.DataDifference, error) {
-	syncTableMap := make(map[string]bool)
-	for _, t := range syncDataTables {
-		syncTableMap[t] = true
+// 行标识的选择顺序：主键 → 全部列非空的唯一索引 → 整行哈希；无法比对的表记录在跳过列表中
+func (c *Comparator) compareTableData(sourceTables, targetTables []string, syncDataTables []string) (map[string]models.DataDifference, []models.SkippedTable, error) {
+	sourceTableMap := make(map[string]bool)
+	for _, t := range sourceTables {
+		sourceTableMap[t] = true
+	}
+
+	targetTableMap := make(map[string]bool)
+	for _, t := range targetTables {
+		targetTableMap[t] = true
 	}
 
 	dataDiffs := make(map[string]models.DataDifference)
+	var skipped []models.SkippedTable
 
-	for _, tableName := range sourceTables {
-		if !syncTableMap[tableName] {
-			continue // 跳过不需要同步数据的表
+	for _, tableName := range syncDataTables {
+		if !sourceTableMap[tableName] {
+			skipped = append(skipped, models.SkippedTable{
+				TableName: tableName,
+				Reason:    "table does not exist in source database",
+			})
+			continue
+		}
+
+		if !targetTableMap[tableName] {
+			skipped = append(skipped, models.SkippedTable{
+				TableName: tableName,
+				Reason:    "table does not exist in target database yet, rerun after the structure is synced",
+			})
+			continue
 		}
 
 		sourceDef, err := c.sourceQueryHelper.GetTableDefinition(tableName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get source table definition: %w", err)
+			return nil, nil, fmt.Errorf("failed to get source table definition: %w", err)
 		}
 
-		// 检查是否有主键
-		if !sourceDef.HasPrimaryKey() {
-			// 跳过没有主键的表
-			continue
+		// 限定了分区的表，两边都必须存在这些分区
+		if partitions := c.options.Partitions[tableName]; len(partitions) > 0 {
+			missing, err := c.missingPartitions(tableName, partitions, sourceDef)
+			if err != nil {
+				return nil, nil, err
+			}
+			if missing != "" {
+				skipped = append(skipped, models.SkippedTable{TableName: tableName, Reason: missing})
+				continue
+			}
 		}
 
-		dataDiff, err := c.compareTableDataByPrimaryKey(tableName, sourceDef.PrimaryKey)
+		var dataDiff models.DataDifference
+		if sourceDef.HasPrimaryKey() {
+			dataDiff, err = c.compareTableDataByKey(sourceDef, sourceDef.PrimaryKey, models.MatchByPrimaryKey)
+		} else if uniqueKey := sourceDef.NotNullUniqueKey(); uniqueKey != nil {
+			dataDiff, err = c.compareTableDataByKey(sourceDef, uniqueKey.Columns, models.MatchByUniqueKey)
+		} else {
+			// 整行哈希要求两边的列完全一致，否则每一行都会被判定为不同
+			targetDef, defErr := c.targetQueryHelper.GetTableDefinition(tableName)
+			if defErr != nil {
+				return nil, nil, fmt.Errorf("failed to get target table definition: %w", defErr)
+			}
+			if !sameColumnNames(sourceDef.Columns, targetDef.Columns) {
+				skipped = append(skipped, models.SkippedTable{
+					TableName: tableName,
+					Reason:    "no primary or NOT NULL unique key and columns differ from target, rerun after the structure is synced",
+				})
+				continue
+			}
+			dataDiff, err = c.compareTableDataByRowHash(tableName, getStoredColumnNames(sourceDef.Columns))
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to compare data for table %s: %w", tableName, err)
+			return nil, nil, fmt.Errorf("failed to compare data for table %s: %w", tableName, err)
 		}
+		dataDiff.GeneratedColumns = getGeneratedColumnNames(sourceDef.Columns)
 
 		dataDiffs[tableName] = dataDiff
 	}
 
-	return dataDiffs, nil
+	return dataDiffs, skipped, nil
+}
+
+// missingPartitions 检查配置的分区是否在两边都存在，返回跳过的原因，都存在时返回空字符串
+func (c *Comparator) missingPartitions(tableName string, partitions []string, sourceDef *models.TableDefinition) (string, error) {
+	targetDef, err := c.targetQueryHelper.GetTableDefinition(tableName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get target table definition: %w", err)
+	}
+
+	for _, side := range []struct {
+		name string
+		def  *models.TableDefinition
+	}{{"source", sourceDef}, {"target", targetDef}} {
+		var existing []string
+		if side.def.Partitioning != nil {
+			existing = side.def.Partitioning.PartitionNames()
+		}
+		for _, partition := range partitions {
+			if !containsFold(existing, partition) {
+				return fmt.Sprintf("partition %s does not exist in %s database", partition, side.name), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// containsFold 判断列表中是否包含某个名称（不区分大小写，与 MySQL 分区名的规则一致）
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareTableDataByKey 按配置的比对模式，使用主键或非空唯一键比对表数据
+// 校验比对要求两边的存储列一致（如本次同步新增了列），否则退回逐行比对
+func (c *Comparator) compareTableDataByKey(tableDef *models.TableDefinition, keyColumns []string, matchMode string) (models.DataDifference, error) {
+	if c.options.Mode == config.CompareModeChecksum {
+		targetDef, err := c.targetQueryHelper.GetTableDefinition(tableDef.TableName)
+		if err != nil {
+			return models.DataDifference{}, fmt.Errorf("failed to get target table definition: %w", err)
+		}
+		if columns, ok := checksumColumns(tableDef.Columns, targetDef.Columns); ok {
+			return c.compareTableDataByChecksum(tableDef, columns, keyColumns, matchMode)
+		}
+	}
+	return c.compareTableDataByPrimaryKey(tableDef, keyColumns, matchMode)
+}
+
+// compareTableDataByPrimaryKey 按主键（或非空唯一键）比对表数据，支持复合键
+// 源库按键顺序分批流式读取，每批的键范围 (上一批末尾, 本批末尾] 用于读取目标库的对应行，
+// 两边在同一键范围内合并比对。两边各只需一次顺序扫描，内存占用与批大小成正比。
+// 键范围的边界由数据库按列的排序规则判断，因此不依赖 Go 端对键值排序。
+func (c *Comparator) compareTableDataByPrimaryKey(tableDef *models.TableDefinition, primaryKeyColumns []string, matchMode string) (models.DataDifference, error) {
+	tableName := tableDef.TableName
+	comparators := c.columnComparators(tableDef)
+	diff := models.DataDifference{
+		TableName:    tableName,
+		KeyColumns:   primaryKeyColumns,
+		MatchMode:    matchMode,
+		RowsToInsert: []map[string]interface{}{},
+		RowsToDelete: []map[string]interface{}{},
+		RowsToUpdate: []models.UpdateRow{},
+	}
+
+	chunkSize := c.options.ChunkSize
+	sourceIter := c.sourceQueryHelper.NewRowIterator(tableName, primaryKeyColumns, chunkSize)
+
+	var lowerBound models.RowKey
+	for {
+		// 读取源库的一批数据
+		var sourceRows []map[string]interface{}
+		for len(sourceRows) < chunkSize && sourceIter.Next() {
+			sourceRows = append(sourceRows, sourceIter.Row())
+		}
+		if err := sourceIter.Err(); err != nil {
+			return diff, err
+		}
+
+		// 不足一批说明源库已读完，目标库剩余的行全部纳入最后一个范围
+		var upperBound models.RowKey
+		if len(sourceRows) == chunkSize {
+			upperBound = models.RowKeyOf(sourceRows[len(sourceRows)-1], primaryKeyColumns)
+		}
+
+		targetIter := c.targetQueryHelper.NewRowIterator(tableName, primaryKeyColumns, chunkSize).
+			WithRange(lowerBound, upperBound)
+		if err := mergeKeyRange(&diff, sourceRows, targetIter, comparators); err != nil {
+			return diff, err
+		}
+
+		if upperBound == nil {
+			break
+		}
+		lowerBound = upperBound
+	}
+
+	return diff, nil
+}
+
+// mergeKeyRange 合并比对同一键范围内的源库行和目标库行
+func mergeKeyRange(diff *models.DataDifference, sourceRows []map[string]interface{}, targetIter *database.RowIterator, comparators map[string]ValueComparator) error {
+	pending := make(map[string]map[string]interface{}, len(sourceRows))
+	for _, row := range sourceRows {
+		pending[models.RowKeyOf(row, diff.KeyColumns).String()] = row
+	}
+
+	for targetIter.Next() {
+		targetRow := targetIter.Row()
+		key := targetIter.Key()
+		keyString := key.String()
+
+		sourceRow, exists := pending[keyString]
+		if !exists {
+			// 目标库多出的行
+			diff.RowsToDelete = append(diff.RowsToDelete, targetRow)
+			continue
+		}
+		delete(pending, keyString)
+
+		if !rowsEqual(sourceRow, targetRow, comparators) {
+			diff.RowsToUpdate = append(diff.RowsToUpdate, models.UpdateRow{
+				Key:       models.RowKeyOf(sourceRow, diff.KeyColumns),
+				OldValues: targetRow,
+				NewValues: sourceRow,
+			})
+		}
+	}
+	if err := targetIter.Err(); err != nil {
+		return err
+	}
+
+	// 按源库顺序输出目标库中不存在的行
+	for _, row := range sourceRows {
+		if _, exists := pending[models.RowKeyOf(row, diff.KeyColumns).String()]; exists {
+			diff.RowsToInsert = append(diff.RowsToInsert, row)
+		}
+	}
+
+	return nil
 }
 
-// compareTableDataByPrimaryKey 按主键比对表数据
-func (c *Comparator) compareTableDataByPrimaryKey(tableName, primaryKeyColumn string) (models.DataDifference, error) {
+// compareTableDataByRowHash 按整行内容比对没有可用键的表
+// 两边的行按多重集处理：相同内容的行出现多次时按次数抵消，剩余的源库行插入、目标库行删除
+func (c *Comparator) compareTableDataByRowHash(tableName string, columns []string) (models.DataDifference, error) {
 	diff := models.DataDifference{
-		TableName:      tableName,
-		PrimaryKeyName: primaryKeyColumn,
-		RowsToInsert:   []map[string]interface{}{},
-		RowsToDelete:   []map[string]interface{}{},
-		RowsToUpdate:   []models.UpdateRow{},
+		TableName:    tableName,
+		KeyColumns:   columns,
+		MatchMode:    models.MatchByRowHash,
+		RowsToInsert: []map[string]interface{}{},
+		RowsToDelete: []map[string]interface{}{},
+		RowsToUpdate: []models.UpdateRow{},
 	}
 
-	// 获取源库和目标库的主键值
-	sourcePKValues, err := c.sourceQueryHelper.GetPrimaryKeyValues(tableName, primaryKeyColumn)
+	sourceRows, err := c.sourceQueryHelper.GetAllRows(tableName)
 	if err != nil {
 		return diff, err
 	}
 
-	targetPKValues, err := c.targetQueryHelper.GetPrimaryKeyValues(tableName, primaryKeyColumn)
+	targetRows, err := c.targetQueryHelper.GetAllRows(tableName)
 	if err != nil {
 		return diff, err
 	}
 
-	// 检查新增行和修改行
-	for pkValue := range sourcePKValues {
-		sourceRow, err := c.sourceQueryHelper.GetRowByPrimaryKey(tableName, primaryKeyColumn, pkValue)
-		if err != nil {
-			return diff, err
-		}
+	diffRowMultisets(&diff, sourceRows, targetRows)
+	return diff, nil
+}
+
+// diffRowMultisets 按多重集比对两边的行：相同内容的行按出现次数抵消，剩余的源库行插入、目标库行删除
+func diffRowMultisets(diff *models.DataDifference, sourceRows, targetRows []map[string]interface{}) {
+	columns := diff.KeyColumns
+
+	// 统计源库中每种行内容出现的次数
+	sourceCounts := make(map[string]int)
+	for _, row := range sourceRows {
+		sourceCounts[models.RowKeyOf(row, columns).String()]++
+	}
 
-		if _, exists := targetPKValues[pkValue]; !exists {
-			// 新增行
-			diff.RowsToInsert = append(diff.RowsToInsert, sourceRow)
+	// 目标库中多出来的行需要删除，已匹配的行从计数中扣除
+	for _, row := range targetRows {
+		hash := models.RowKeyOf(row, columns).String()
+		if sourceCounts[hash] > 0 {
+			sourceCounts[hash]--
 		} else {
-			// 可能修改了，需要对比
-			targetRow, err := c.targetQueryHelper.GetRowByPrimaryKey(tableName, primaryKeyColumn, pkValue)
-			if err != nil {
-				return diff, err
-			}
+			diff.RowsToDelete = append(diff.RowsToDelete, row)
+		}
+	}
 
-			if !rowsEqual(sourceRow, targetRow) {
-				diff.RowsToUpdate = append(diff.RowsToUpdate, models.UpdateRow{
-					PrimaryKeyValue: pkValue,
-					OldValues:       targetRow,
-					NewValues:       sourceRow,
-				})
-			}
+	// 计数仍有剩余的源库行需要插入
+	for _, row := range sourceRows {
+		hash := models.RowKeyOf(row, columns).String()
+		if sourceCounts[hash] > 0 {
+			sourceCounts[hash]--
+			diff.RowsToInsert = append(diff.RowsToInsert, row)
 		}
 	}
+}
 
-	// 检查删除行
-	for pkValue := range targetPKValues {
-		if _, exists := sourcePKValues[pkValue]; !exists {
-			// 删除行
-			targetRow, err := c.targetQueryHelper.GetRowByPrimaryKey(tableName, primaryKeyColumn, pkValue)
-			if err != nil {
-				return diff, err
-			}
-			diff.RowsToDelete = append(diff.RowsToDelete, targetRow)
+// sameColumnNames 判断两个表的列名集合是否一致
+func sameColumnNames(columns1, columns2 []models.Column) bool {
+	if len(columns1) != len(columns2) {
+		return false
+	}
+	names := make(map[string]bool)
+	for _, col := range columns1 {
+		names[col.Name] = true
+	}
+	for _, col := range columns2 {
+		if !names[col.Name] {
+			return false
 		}
 	}
+	return true
+}
 
-	return diff, nil
+// columnComparators 为表的每一列选择值比较器
+func (c *Comparator) columnComparators(tableDef *models.TableDefinition) map[string]ValueComparator {
+	comparators := make(map[string]ValueComparator, len(tableDef.Columns))
+	for _, col := range tableDef.Columns {
+		if col.IsGenerated() {
+			// 生成列的值由其他列计算得出，不参与比对
+			comparators[col.Name] = ValueComparatorFunc(func(a, b interface{}) bool { return true })
+			continue
+		}
+		comparators[col.Name] = valueComparatorFor(col, c.options.FloatEpsilon)
+	}
+	return comparators
 }
 
-// rowsEqual 判断两行数据是否相等
-func rowsEqual(row1, row2 map[string]interface{}) bool {
+// rowsEqual 判断两行数据是否相等，每列使用其类型对应的比较器
+func rowsEqual(row1, row2 map[string]interface{}, comparators map[string]ValueComparator) bool {
 	if len(row1) != len(row2) {
 		return false
 	}
@@ -391,8 +1369,11 @@ func rowsEqual(row1, row2 map[string]interface{}) bool {
 			return false
 		}
 
-		// 比对值
-		if fmt.Sprintf("%v", val1) != fmt.Sprintf("%v", val2) {
+		comparator, exists := comparators[key]
+		if !exists {
+			comparator = defaultValueComparator
+		}
+		if !comparator.Equal(val1, val2) {
 			return false
 		}
 	}
@@ -400,6 +1381,15 @@ func rowsEqual(row1, row2 map[string]interface{}) bool {
 	return true
 }
 
+// formatValue 将值格式化为用于比对的字符串
+// []byte 转为 string，避免文本协议与二进制协议返回的类型不同导致误判
+func formatValue(val interface{}) string {
+	if b, ok := val.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
 // compareViews 比对视图差异
 func (c *Comparator) compareViews() ([]models.ViewDifference, error) {
 	sourceViews, err := c.sourceQueryHelper.GetViews()
@@ -435,7 +1425,7 @@ func (c *Comparator) compareViews() ([]models.ViewDifference, error) {
 			})
 		} else {
 			// 检查定义是否相同
-			if normalizeViewDefinition(sourceView.Definition) != normalizeViewDefinition(targetView.Definition) {
+			if c.normalizer.viewDefinition(sourceView.Definition) != c.normalizer.viewDefinition(targetView.Definition) {
 				// 修改视图
 				viewDiffs = append(viewDiffs, models.ViewDifference{
 					ViewName:      sourceView.ViewName,
@@ -483,3 +1473,25 @@ func getColumnNames(columns []models.Column) []string {
 	}
 	return names
 }
+
+// getStoredColumnNames 获取非生成列的列名列表
+func getStoredColumnNames(columns []models.Column) []string {
+	var names []string
+	for _, col := range columns {
+		if !col.IsGenerated() {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+// getGeneratedColumnNames 获取生成列的列名列表
+func getGeneratedColumnNames(columns []models.Column) []string {
+	var names []string
+	for _, col := range columns {
+		if col.IsGenerated() {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,451 @@
+package sync
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yuhuo/sync-db/config"
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestCompareIndexes(t *testing.T) {
+	c := &Comparator{}
+
+	source := []models.Index{
+		{Name: "uk_email", Type: "UNIQUE", Visible: true, Columns: []string{"email"},
+			Parts: []models.IndexPart{{Column: "email"}}},
+		{Name: "idx_name_new", Type: "INDEX", Visible: true, Columns: []string{"name"},
+			Parts: []models.IndexPart{{Column: "name", SubPart: 10}}},
+	}
+	target := []models.Index{
+		{Name: "uk_email", Type: "INDEX", Visible: true, Columns: []string{"email"},
+			Parts: []models.IndexPart{{Column: "email"}}},
+		{Name: "idx_name", Type: "INDEX", Visible: true, Columns: []string{"name"},
+			Parts: []models.IndexPart{{Column: "name", SubPart: 10}}},
+	}
+
+	result := c.compareIndexes(source, target)
+
+	if len(result.added) != 0 || len(result.deleted) != 0 {
+		t.Errorf("Expected no added or deleted indexes, got %d added, %d deleted", len(result.added), len(result.deleted))
+	}
+	if len(result.modified) != 1 || result.modified[0].IndexName != "uk_email" {
+		t.Errorf("Expected uk_email to be modified, got %+v", result.modified)
+	}
+	if len(result.renamed) != 1 || result.renamed[0].OldName != "idx_name" || result.renamed[0].NewName != "idx_name_new" {
+		t.Errorf("Expected idx_name to be renamed to idx_name_new, got %+v", result.renamed)
+	}
+}
+
+func TestNormalizeCheckExpression(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"(`price` > 0)", "`price` > 0"},
+		{"((`price`  >\n 0))", "`price` > 0"},
+		{"(`a` > 0) and (`b` > 0)", "(`a` > 0) and (`b` > 0)"},
+		{"(`note` <> ')(')", "`note` <> ')('"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeExpression(tt.expr); got != tt.expected {
+			t.Errorf("Expected normalizeExpression(%q) to be %q, got %q", tt.expr, tt.expected, got)
+		}
+	}
+}
+
+func TestCompareColumnOrder(t *testing.T) {
+	columns := func(names ...string) []models.Column {
+		var cols []models.Column
+		for _, name := range names {
+			cols = append(cols, models.Column{Name: name})
+		}
+		return cols
+	}
+
+	moved := compareColumnOrder(columns("id", "name", "email", "created_at"), columns("id", "email", "created_at", "name", "legacy"))
+	if len(moved) != 1 || moved[0].Name != "name" {
+		t.Errorf("Expected only `name` to be moved, got %v", moved)
+	}
+
+	if moved := compareColumnOrder(columns("id", "name"), columns("id", "old", "name")); len(moved) != 0 {
+		t.Errorf("Expected no moved columns, got %v", moved)
+	}
+}
+
+func TestDetectColumnRenames(t *testing.T) {
+	source := []models.Column{
+		{Name: "id", Position: 1, Type: "int"},
+		{Name: "username", Position: 2, Type: "varchar(50)"},
+		{Name: "mail", Position: 3, Type: "varchar(100)"},
+	}
+	target := []models.Column{
+		{Name: "id", Position: 1, Type: "int"},
+		{Name: "user_name", Position: 2, Type: "varchar(50)"},
+		{Name: "email", Position: 3, Type: "varchar(255)"},
+	}
+
+	c := &Comparator{}
+	colDiff, colMod := c.compareColumns(source, target)
+	diff := models.StructureDifference{ColumnsAdded: colDiff.added, ColumnsDeleted: colDiff.deleted, ColumnsModified: colMod}
+	detectColumnRenames(schemaNormalizer{}, map[string]string{"email": "mail"}, source, target, &diff)
+
+	if len(diff.ColumnsRenamed) != 2 {
+		t.Fatalf("Expected 2 renames, got %d", len(diff.ColumnsRenamed))
+	}
+	declared, detected := diff.ColumnsRenamed[0], diff.ColumnsRenamed[1]
+	if declared.OldName != "email" || declared.NewName != "mail" || !declared.Confirmed {
+		t.Errorf("Expected declared rename email → mail to be confirmed, got %+v", declared)
+	}
+	if detected.OldName != "user_name" || detected.NewName != "username" || detected.Confirmed {
+		t.Errorf("Expected unconfirmed rename user_name → username, got %+v", detected)
+	}
+	if len(diff.ColumnsAdded) != 1 || len(diff.ColumnsDeleted) != 1 {
+		t.Errorf("Expected unconfirmed rename to stay as add and delete, got %d added, %d deleted",
+			len(diff.ColumnsAdded), len(diff.ColumnsDeleted))
+	}
+
+	diff.ConfirmColumnRename(1)
+	if len(diff.ColumnsAdded) != 0 || len(diff.ColumnsDeleted) != 0 {
+		t.Errorf("Expected no added or deleted columns after confirmation, got %d added, %d deleted",
+			len(diff.ColumnsAdded), len(diff.ColumnsDeleted))
+	}
+}
+
+func TestDetectColumnRenamesNormalizesDefinitions(t *testing.T) {
+	utf8, utf8mb3 := "utf8", "utf8mb3"
+	source := []models.Column{
+		{Name: "id", Position: 1, Type: "int"},
+		{Name: "nickname", Position: 2, Type: "varchar(50)", Charset: &utf8mb3},
+	}
+	target := []models.Column{
+		{Name: "id", Position: 1, Type: "int"},
+		{Name: "nick", Position: 2, Type: "varchar(50)", Charset: &utf8},
+	}
+
+	// utf8 与 utf8mb3 只是不同版本的写法，与 compareColumns 一样视为相同定义
+	diff := models.StructureDifference{ColumnsAdded: source[1:], ColumnsDeleted: []string{"nick"}}
+	detectColumnRenames(schemaNormalizer{}, nil, source, target, &diff)
+	if len(diff.ColumnsRenamed) != 1 || diff.ColumnsRenamed[0].OldName != "nick" {
+		t.Errorf("Expected rename nick → nickname to be detected, got %+v", diff.ColumnsRenamed)
+	}
+}
+
+func TestCompareColumnCharsets(t *testing.T) {
+	utf8, utf8mb4 := "utf8mb3_general_ci", "utf8mb4_0900_ai_ci"
+	source := []models.Column{
+		{Name: "name", Type: "varchar(50)", Collation: &utf8mb4},
+		{Name: "title", Type: "varchar(100)", Collation: &utf8mb4},
+		{Name: "id", Type: "int"},
+	}
+	target := []models.Column{
+		{Name: "name", Type: "varchar(50)", Collation: &utf8},
+		{Name: "title", Type: "varchar(80)", Collation: &utf8},
+		{Name: "id", Type: "int"},
+	}
+
+	modified := []models.ColumnModification{{ColumnName: "title"}}
+	changes := compareColumnCharsets(source, target, modified)
+	if len(changes) != 1 || changes[0].ColumnName != "name" || changes[0].OldCollation != utf8 || changes[0].NewCollation != utf8mb4 {
+		t.Errorf("Expected only name to drift from %s to %s, got %+v", utf8, utf8mb4, changes)
+	}
+}
+
+func TestDiffRowMultisets(t *testing.T) {
+	row := func(name interface{}, qty int64) map[string]interface{} {
+		return map[string]interface{}{"name": name, "qty": qty}
+	}
+	source := []map[string]interface{}{row("a", 1), row("a", 1), row([]byte("b"), 2), row(nil, 3)}
+	target := []map[string]interface{}{row("a", 1), row("b", 2), row("", 3), row("c", 4)}
+
+	diff := models.DataDifference{KeyColumns: []string{"name", "qty"}}
+	diffRowMultisets(&diff, source, target)
+
+	if len(diff.RowsToInsert) != 2 || diff.RowsToInsert[0]["name"] != "a" || diff.RowsToInsert[1]["name"] != nil {
+		t.Errorf("Expected the duplicate a row and the NULL row to be inserted, got %v", diff.RowsToInsert)
+	}
+	if len(diff.RowsToDelete) != 2 || diff.RowsToDelete[0]["name"] != "" || diff.RowsToDelete[1]["name"] != "c" {
+		t.Errorf("Expected the empty-string row and the c row to be deleted, got %v", diff.RowsToDelete)
+	}
+}
+
+func TestCheckViolation(t *testing.T) {
+	check := models.CheckConstraint{Name: "chk_price", Expression: "(`price` > 0)", Enforced: true}
+
+	if _, ok := checkViolation(check, 0, nil, nil); ok {
+		t.Error("Expected no violation when no rows violate the constraint")
+	}
+	if v, ok := checkViolation(check, 3, nil, nil); !ok || v.RowCount != 3 || v.Error != "" {
+		t.Errorf("Expected violation with 3 rows, got %+v", v)
+	}
+	// 无法在目标库执行的表达式不能当作没有违反的行
+	v, ok := checkViolation(check, 0, nil, errors.New("Unknown column 'price' in 'where clause'"))
+	if !ok || v.Error == "" {
+		t.Errorf("Expected unvalidated constraint to be recorded, got %+v", v)
+	}
+}
+
+func TestMatchTableRenames(t *testing.T) {
+	columns := []models.Column{{Name: "id", Position: 1, Type: "int"}, {Name: "name", Position: 2, Type: "varchar(50)", IsNullable: true}}
+	newTable := func(name string) models.StructureDifference {
+		def := &models.TableDefinition{TableName: name, Columns: columns, Engine: "InnoDB", RowFormat: "Dynamic"}
+		return models.StructureDifference{TableName: name, IsNewTable: true, TableDefinition: def}
+	}
+	orphan := func(name, engine string, cols []models.Column) *models.TableDefinition {
+		return &models.TableDefinition{TableName: name, Columns: cols, Engine: engine, RowFormat: "Dynamic"}
+	}
+
+	c := &Comparator{}
+	structDiffs := []models.StructureDifference{newTable("members")}
+	renames := c.matchTableRenames(structDiffs, []*models.TableDefinition{
+		orphan("users", "MyISAM", columns),
+		orphan("logs", "InnoDB", columns[:1]),
+	})
+	if len(renames) != 1 || renames[0].OldName != "users" || renames[0].NewName != "members" || renames[0].Confirmed {
+		t.Fatalf("Expected unconfirmed rename users → members, got %+v", renames)
+	}
+
+	// 确认改名后保留表选项的差异，在改名之后修改
+	diff := &models.SyncDifference{StructureDifferences: structDiffs, TableRenames: renames}
+	diff.ConfirmTableRename(0)
+	sd := diff.StructureDifferences[0]
+	if sd.RenamedFrom != "users" || sd.IsNewTable {
+		t.Errorf("Expected table to be renamed from users, got %+v", sd)
+	}
+	if len(sd.OptionsModified) != 1 || sd.OptionsModified[0].Option != "ENGINE" || sd.OptionsModified[0].NewValue != "InnoDB" {
+		t.Errorf("Expected ENGINE change to be kept after rename, got %+v", sd.OptionsModified)
+	}
+
+	// 多个结构相同的候选时不推测
+	renames = c.matchTableRenames([]models.StructureDifference{newTable("members")}, []*models.TableDefinition{
+		orphan("users", "InnoDB", columns),
+		orphan("users_old", "InnoDB", columns),
+	})
+	if len(renames) != 0 {
+		t.Errorf("Expected no rename with ambiguous candidates, got %+v", renames)
+	}
+}
+
+func TestFindOrphanTables(t *testing.T) {
+	source := []string{"users", "orders"}
+	target := []string{"users", "orders", "legacy", "members_old", "audit_archived_20260101093000", "audit_archived_old"}
+	renames := []models.TableRename{
+		{OldName: "members_old", NewName: "members", Confirmed: true},
+	}
+
+	tests := []struct {
+		policy   config.OrphanTableConfig
+		expected []models.OrphanTable
+	}{
+		{config.OrphanTableConfig{Policy: config.OrphanPolicyIgnore}, nil},
+		{config.OrphanTableConfig{Policy: config.OrphanPolicyReport}, []models.OrphanTable{
+			{TableName: "legacy", Action: models.OrphanReport},
+			{TableName: "audit_archived_old", Action: models.OrphanReport},
+		}},
+		{config.OrphanTableConfig{Policy: config.OrphanPolicyDrop, Archive: true}, []models.OrphanTable{
+			{TableName: "legacy", Action: models.OrphanArchive},
+			{TableName: "audit_archived_old", Action: models.OrphanArchive},
+		}},
+	}
+
+	for _, tt := range tests {
+		c := (&Comparator{}).WithOrphanTables(tt.policy)
+		// 已确认改名的旧表和已归档（_archived_ 加 14 位时间戳）的表不是孤立表
+		if got := c.findOrphanTables(source, target, renames); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.policy.Policy, tt.expected, got)
+		}
+	}
+}
+
+func TestCharsetDriftDisabled(t *testing.T) {
+	latin1, latin1Ci, utf8mb4, utf8mb4Ci := "latin1", "latin1_swedish_ci", "utf8mb4", "utf8mb4_0900_ai_ci"
+	source := &models.TableDefinition{TableName: "users", Charset: &utf8mb4, Collation: &utf8mb4Ci, Columns: []models.Column{
+		{Name: "name", Position: 1, Type: "varchar(100)", Charset: &utf8mb4, Collation: &utf8mb4Ci},
+	}}
+	target := &models.TableDefinition{TableName: "users", Charset: &latin1, Collation: &latin1Ci, Columns: []models.Column{
+		{Name: "name", Position: 1, Type: "varchar(50)", Charset: &latin1, Collation: &latin1Ci},
+	}}
+
+	c := &Comparator{}
+	diff := c.compareTableDefinitions("users", "users", source, target)
+	if len(diff.OptionsModified) != 0 {
+		t.Errorf("Expected table charset to be ignored without charset_drift, got %+v", diff.OptionsModified)
+	}
+	if len(diff.ColumnsModified) != 1 {
+		t.Fatalf("Expected 1 modified column, got %d", len(diff.ColumnsModified))
+	}
+	// 修改列时保留目标库的字符集，不顺带转换
+	if col := diff.ColumnsModified[0].NewColumn; col.Type != "varchar(100)" || *col.Collation != latin1Ci {
+		t.Errorf("Expected varchar(100) with target collation, got %s %s", col.Type, *col.Collation)
+	}
+
+	c.options.CharsetDrift = true
+	diff = c.compareTableDefinitions("users", "users", source, target)
+	if len(diff.OptionsModified) != 1 || diff.OptionsModified[0].Option != "CONVERT TO CHARACTER SET" {
+		t.Errorf("Expected table conversion with charset_drift, got %+v", diff.OptionsModified)
+	}
+	if col := diff.ColumnsModified[0].NewColumn; *col.Collation != utf8mb4Ci {
+		t.Errorf("Expected source collation with charset_drift, got %s", *col.Collation)
+	}
+}
+
+func TestCompareForeignKeys(t *testing.T) {
+	fk := func(name, column, onDelete string) models.ForeignKey {
+		return models.ForeignKey{Name: name, Columns: []string{column}, ReferencedTable: "users",
+			ReferencedColumns: []string{"id"}, OnDelete: onDelete, OnUpdate: "RESTRICT"}
+	}
+	source := []models.ForeignKey{
+		fk("fk_owner", "owner_id", "RESTRICT"),
+		fk("fk_author", "author_id", "CASCADE"),
+		fk("fk_editor", "editor_id", "RESTRICT"),
+	}
+	target := []models.ForeignKey{
+		fk("fk_owner", "owner_id", "RESTRICT"),
+		fk("fk_author", "author_id", "RESTRICT"),
+		fk("fk_reviewer", "reviewer_id", "RESTRICT"),
+	}
+
+	added, deleted := compareForeignKeys(source, target)
+	// ON DELETE 规则变化的外键先删除再新增，未变化的外键不出现
+	var addedNames, deletedNames []string
+	for _, fk := range added {
+		addedNames = append(addedNames, fk.Name)
+	}
+	for _, fk := range deleted {
+		deletedNames = append(deletedNames, fk.Name)
+	}
+	if !reflect.DeepEqual(addedNames, []string{"fk_author", "fk_editor"}) {
+		t.Errorf("Expected added [fk_author fk_editor], got %v", addedNames)
+	}
+	if !reflect.DeepEqual(deletedNames, []string{"fk_author", "fk_reviewer"}) {
+		t.Errorf("Expected deleted [fk_author fk_reviewer], got %v", deletedNames)
+	}
+	if added[0].OnDelete != "CASCADE" || deleted[0].OnDelete != "RESTRICT" {
+		t.Errorf("Expected source definition added and target definition deleted, got %+v / %+v", added[0], deleted[0])
+	}
+}
+
+func TestRebuildForeignKeysOnDroppedIndexes(t *testing.T) {
+	index := func(name string, columns ...string) models.Index {
+		idx := models.Index{Name: name, Type: "INDEX", Columns: columns, Visible: true}
+		for _, col := range columns {
+			idx.Parts = append(idx.Parts, models.IndexPart{Column: col})
+		}
+		return idx
+	}
+	ownerFK := models.ForeignKey{Name: "fk_owner", Columns: []string{"owner_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}}
+	targetDef := &models.TableDefinition{
+		TableName:   "orders",
+		Indexes:     []models.Index{index("idx_owner", "owner_id"), index("idx_owner_status", "owner_id", "status"), index("idx_status", "status")},
+		ForeignKeys: []models.ForeignKey{ownerFK},
+	}
+
+	// 还有其他索引可用于外键时直接删除
+	diff := models.StructureDifference{IndexesDeleted: []models.Index{index("idx_owner", "owner_id")}}
+	rebuildForeignKeysOnDroppedIndexes(&diff, targetDef)
+	if len(diff.ForeignKeysDeleted) != 0 || len(diff.ForeignKeysAdded) != 0 {
+		t.Errorf("Expected foreign key to be kept, got %+v", diff)
+	}
+
+	// 删除外键唯一可用的索引时，外键在删除索引前删除、之后重新添加
+	diff = models.StructureDifference{
+		IndexesDeleted:  []models.Index{index("idx_owner", "owner_id")},
+		IndexesModified: []models.IndexModification{{IndexName: "idx_owner_status", OldIndex: index("idx_owner_status", "owner_id", "status"), NewIndex: index("idx_owner_status", "status", "owner_id")}},
+	}
+	rebuildForeignKeysOnDroppedIndexes(&diff, targetDef)
+	if len(diff.ForeignKeysDeleted) != 1 || len(diff.ForeignKeysAdded) != 1 || diff.ForeignKeysAdded[0].Name != "fk_owner" {
+		t.Errorf("Expected fk_owner to be dropped and re-added, got %+v / %+v", diff.ForeignKeysDeleted, diff.ForeignKeysAdded)
+	}
+}
+
+func TestCompareTableOptions(t *testing.T) {
+	str := func(s string) *string { return &s }
+	table := func(modify func(def *models.TableDefinition)) *models.TableDefinition {
+		def := &models.TableDefinition{
+			TableName: "orders", Engine: "InnoDB", RowFormat: "Dynamic", Comment: "orders",
+			Charset: str("utf8mb4"), Collation: str("utf8mb4_general_ci"), AutoIncrement: 100,
+			Columns: []models.Column{{Name: "id", Type: "int"}, {Name: "note", Type: "varchar(64)", Charset: str("utf8mb4"), Collation: str("utf8mb4_general_ci")}},
+		}
+		if modify != nil {
+			modify(def)
+		}
+		return def
+	}
+
+	tests := []struct {
+		name           string
+		source         *models.TableDefinition
+		target         *models.TableDefinition
+		compareCharset bool
+		expected       []models.TableOptionChange
+	}{
+		{"unchanged", table(nil), table(nil), true, nil},
+		{
+			"engine, row format and key block size rebuild the table",
+			table(func(def *models.TableDefinition) { def.RowFormat = "Compressed"; def.KeyBlockSize = 8 }),
+			table(func(def *models.TableDefinition) { def.Engine = "MyISAM" }),
+			true,
+			[]models.TableOptionChange{
+				{Option: "ENGINE", OldValue: "MyISAM", NewValue: "InnoDB", Rebuild: true},
+				{Option: "ROW_FORMAT", OldValue: "Dynamic", NewValue: "Compressed", Rebuild: true},
+				{Option: "KEY_BLOCK_SIZE", OldValue: "0", NewValue: "8", Rebuild: true},
+			},
+		},
+		{
+			"compression and comment",
+			table(func(def *models.TableDefinition) { def.Compression = "zlib"; def.Comment = "customer orders" }),
+			table(nil),
+			true,
+			[]models.TableOptionChange{
+				{Option: "COMPRESSION", OldValue: "", NewValue: "zlib"},
+				{Option: "COMMENT", OldValue: "orders", NewValue: "customer orders"},
+			},
+		},
+		{
+			"charset converted when every column follows the table",
+			table(func(def *models.TableDefinition) {
+				def.Collation = str("utf8mb4_unicode_ci")
+				def.Columns[1].Collation = str("utf8mb4_unicode_ci")
+			}),
+			table(nil),
+			true,
+			[]models.TableOptionChange{{Option: "CONVERT TO CHARACTER SET", OldValue: "utf8mb4_general_ci", NewValue: "utf8mb4_unicode_ci", Rebuild: true}},
+		},
+		{
+			"only default charset when a column keeps its own",
+			table(func(def *models.TableDefinition) { def.Collation = str("utf8mb4_unicode_ci") }),
+			table(nil),
+			true,
+			[]models.TableOptionChange{{Option: "DEFAULT CHARACTER SET", OldValue: "utf8mb4_general_ci", NewValue: "utf8mb4_unicode_ci"}},
+		},
+		{
+			"charset ignored without charset drift",
+			table(func(def *models.TableDefinition) { def.Collation = str("utf8mb4_unicode_ci") }),
+			table(nil),
+			false,
+			nil,
+		},
+		{
+			"auto increment raised",
+			table(func(def *models.TableDefinition) { def.AutoIncrement = 250 }),
+			table(nil),
+			true,
+			[]models.TableOptionChange{{Option: "AUTO_INCREMENT", OldValue: "100", NewValue: "250"}},
+		},
+		{
+			"auto increment never lowered",
+			table(nil),
+			table(func(def *models.TableDefinition) { def.AutoIncrement = 250 }),
+			true,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := compareTableOptions(tt.source, tt.target, tt.compareCharset); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, got)
+		}
+	}
+}
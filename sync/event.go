@@ -0,0 +1,222 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// compareEvents 比对定时事件差异
+// 比对执行计划、状态、ON COMPLETION、注释和事件语句（忽略空白差异）
+func (c *Comparator) compareEvents() ([]models.EventDifference, error) {
+	sourceEvents, err := c.sourceQueryHelper.GetEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	targetEvents, err := c.targetQueryHelper.GetEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	sourceEventMap := make(map[string]models.EventDefinition)
+	for _, event := range sourceEvents {
+		sourceEventMap[event.EventName] = event
+	}
+
+	targetEventMap := make(map[string]models.EventDefinition)
+	for _, event := range targetEvents {
+		targetEventMap[event.EventName] = event
+	}
+
+	var eventDiffs []models.EventDifference
+
+	// 检查新增和修改的事件
+	for i := range sourceEvents {
+		sourceEvent := &sourceEvents[i]
+		targetEvent, exists := targetEventMap[sourceEvent.EventName]
+		if !exists {
+			eventDiffs = append(eventDiffs, models.EventDifference{
+				EventName: sourceEvent.EventName,
+				Operation: "CREATE",
+				NewEvent:  sourceEvent,
+			})
+		} else if !eventsEqual(*sourceEvent, targetEvent, true) {
+			eventDiffs = append(eventDiffs, models.EventDifference{
+				EventName: sourceEvent.EventName,
+				Operation: "MODIFY",
+				OldEvent:  &targetEvent,
+				NewEvent:  sourceEvent,
+			})
+		}
+	}
+
+	// 检查删除的事件
+	for i := range targetEvents {
+		targetEvent := &targetEvents[i]
+		if _, exists := sourceEventMap[targetEvent.EventName]; !exists {
+			eventDiffs = append(eventDiffs, models.EventDifference{
+				EventName: targetEvent.EventName,
+				Operation: "DROP",
+				OldEvent:  targetEvent,
+			})
+		}
+	}
+
+	return eventDiffs, nil
+}
+
+// eventsEqual 判断两个事件定义是否相同，compareStatus 为 false 时忽略启用状态
+func eventsEqual(e1, e2 models.EventDefinition, compareStatus bool) bool {
+	if compareStatus && e1.Status != e2.Status {
+		return false
+	}
+	now := time.Now()
+	return comparableSchedule(e1, now) == comparableSchedule(e2, now) &&
+		e1.OnCompletion == e2.OnCompletion &&
+		e1.Comment == e2.Comment &&
+		normalizeWhitespace(e1.Body) == normalizeWhitespace(e2.Body)
+}
+
+// comparableSchedule 返回用于比对的执行计划
+// 创建周期事件时省略 STARTS 会以创建时间作为开始时间，各库分别创建的同一事件开始时间必然不同，
+// 因此已经开始的事件忽略 STARTS；计划中含有时间时带上事件的时区，同一时间在不同时区下并不相同
+func comparableSchedule(event models.EventDefinition, now time.Time) string {
+	loc := eventLocation(event.TimeZone)
+	if event.Starts != nil && !inLocation(*event.Starts, loc).After(now) {
+		event.Starts = nil
+	}
+	schedule := eventSchedule(event)
+	if event.ExecuteAt != nil || event.Starts != nil || event.Ends != nil {
+		schedule += " TIME_ZONE " + event.TimeZone
+	}
+	return schedule
+}
+
+// eventLocation 返回事件时区对应的 Location，SYSTEM 或无法识别的时区按本地时区处理
+func eventLocation(timeZone string) *time.Location {
+	var sign byte
+	var hours, minutes int
+	if n, _ := fmt.Sscanf(timeZone, "%c%d:%d", &sign, &hours, &minutes); n == 3 && (sign == '+' || sign == '-') {
+		offset := hours*3600 + minutes*60
+		if sign == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(timeZone, offset)
+	}
+	if timeZone != "SYSTEM" {
+		if loc, err := time.LoadLocation(timeZone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// inLocation 将读取到的时间（驱动按 UTC 解析的事件时区下的时间）解释为指定时区下的时间
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// eventSchedule 生成事件的 ON SCHEDULE 子句内容，如 "EVERY '1' DAY STARTS '2026-01-01 03:00:00'"
+func eventSchedule(event models.EventDefinition) string {
+	if event.EventType == "ONE TIME" {
+		if event.ExecuteAt == nil {
+			return "AT CURRENT_TIMESTAMP"
+		}
+		return "AT '" + formatDateTime(*event.ExecuteAt) + "'"
+	}
+
+	schedule := fmt.Sprintf("EVERY %s %s", quoteString(event.IntervalValue), event.IntervalField)
+	if event.Starts != nil {
+		schedule += " STARTS '" + formatDateTime(*event.Starts) + "'"
+	}
+	if event.Ends != nil {
+		schedule += " ENDS '" + formatDateTime(*event.Ends) + "'"
+	}
+	return schedule
+}
+
+// eventStatusClause 将事件状态转换为 CREATE/ALTER EVENT 中的状态子句
+func eventStatusClause(status string) string {
+	switch status {
+	case "DISABLED":
+		return "DISABLE"
+	case "SLAVESIDE_DISABLED":
+		return "DISABLE ON SLAVE"
+	default:
+		return "ENABLE"
+	}
+}
+
+// generateDropEventSQL 生成删除事件的语句
+func generateDropEventSQL(eventDiffs []models.EventDifference) []models.Statement {
+	var stmts []models.Statement
+	for _, eventDiff := range eventDiffs {
+		if eventDiff.Operation == "DROP" {
+			stmts = append(stmts, models.Statement{
+				Kind:   models.StatementDropEvent,
+				Table:  eventDiff.EventName,
+				Object: fmt.Sprintf("event `%s`", eventDiff.EventName),
+				SQL:    fmt.Sprintf("DROP EVENT IF EXISTS `%s`", eventDiff.EventName),
+			})
+		}
+	}
+	return stmts
+}
+
+// generateEventSQL 生成创建和修改事件的语句
+// 开启 CreateEventsDisabled 时新建事件一律为 DISABLE，修改事件时不改变目标库中的启用状态，
+// 仅启用状态不同的事件不生成语句，避免同步过程中意外启动定时任务
+func (sg *SQLGenerator) generateEventSQL(eventDiffs []models.EventDifference) []models.Statement {
+	var stmts []models.Statement
+	for _, eventDiff := range eventDiffs {
+		event := eventDiff.NewEvent
+		switch eventDiff.Operation {
+		case "CREATE":
+			status := eventStatusClause(event.Status)
+			if sg.options.CreateEventsDisabled {
+				status = "DISABLE"
+			}
+			stmts = append(stmts, models.Statement{
+				Kind:     models.StatementCreateEvent,
+				Table:    event.EventName,
+				Object:   fmt.Sprintf("event `%s`", event.EventName),
+				SQL:      buildEventSQL("CREATE", *event, status),
+				TimeZone: event.TimeZone,
+			})
+		case "MODIFY":
+			status := eventStatusClause(event.Status)
+			if sg.options.CreateEventsDisabled {
+				if eventsEqual(*event, *eventDiff.OldEvent, false) {
+					continue
+				}
+				status = ""
+			}
+			stmts = append(stmts, models.Statement{
+				Kind:     models.StatementAlterEvent,
+				Table:    event.EventName,
+				Object:   fmt.Sprintf("event `%s`", event.EventName),
+				SQL:      buildEventSQL("ALTER", *event, status),
+				TimeZone: event.TimeZone,
+			})
+		}
+	}
+	return stmts
+}
+
+// buildEventSQL 生成 CREATE EVENT 或 ALTER EVENT 语句，status 为空时不包含状态子句
+func buildEventSQL(verb string, event models.EventDefinition, status string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s EVENT `%s` ON SCHEDULE %s ON COMPLETION %s", verb, event.EventName, eventSchedule(event), event.OnCompletion)
+	if status != "" {
+		sb.WriteString(" " + status)
+	}
+	// ALTER 时总是带上注释，以便清除目标库中多余的注释
+	if event.Comment != "" || verb == "ALTER" {
+		sb.WriteString(" COMMENT " + quoteString(event.Comment))
+	}
+	sb.WriteString(" DO " + event.Body)
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestEventsEqualSchedule(t *testing.T) {
+	at := func(s string) *time.Time {
+		v, err := time.Parse("2006-01-02 15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &v
+	}
+	event := func(starts, timeZone string) models.EventDefinition {
+		return models.EventDefinition{
+			EventName: "ev_cleanup", EventType: "RECURRING", IntervalValue: "1", IntervalField: "DAY",
+			Starts: at(starts), Status: "ENABLED", OnCompletion: "NOT PRESERVE", Body: "DELETE FROM `logs`", TimeZone: timeZone,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		e1, e2 models.EventDefinition
+		equal  bool
+	}{
+		// 省略 STARTS 创建的事件以各自的创建时间作为开始时间
+		{"past starts ignored", event("2024-03-01 10:00:00", "SYSTEM"), event("2025-07-15 08:30:00", "SYSTEM"), true},
+		{"future starts compared", event("2099-01-01 03:00:00", "SYSTEM"), event("2099-01-02 03:00:00", "SYSTEM"), false},
+		{"same future starts", event("2099-01-01 03:00:00", "+08:00"), event("2099-01-01 03:00:00", "+08:00"), true},
+		{"future starts in different time zones", event("2099-01-01 03:00:00", "+08:00"), event("2099-01-01 03:00:00", "+00:00"), false},
+		{"time zone ignored without times", event("2024-03-01 10:00:00", "+08:00"), event("2024-03-01 10:00:00", "SYSTEM"), true},
+	}
+
+	for _, tt := range tests {
+		if got := eventsEqual(tt.e1, tt.e2, true); got != tt.equal {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.equal, got)
+		}
+	}
+}
+
+func TestEventLocation(t *testing.T) {
+	when := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		timeZone string
+		offset   int
+	}{
+		{"+08:00", 8 * 3600},
+		{"-05:30", -(5*3600 + 30*60)},
+		{"UTC", 0},
+	}
+
+	for _, tt := range tests {
+		if _, offset := when.In(eventLocation(tt.timeZone)).Zone(); offset != tt.offset {
+			t.Errorf("%s: expected offset %d, got %d", tt.timeZone, tt.offset, offset)
+		}
+	}
+	if eventLocation("SYSTEM") != time.Local {
+		t.Error("Expected SYSTEM to use the local time zone")
+	}
+}
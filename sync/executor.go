@@ -6,11 +6,13 @@ import (
 
 	"github.com/yuhuo/sync-db/database"
 	"github.com/yuhuo/sync-db/logger"
+	"github.com/yuhuo/sync-db/models"
 )
 
 // ExecutionResult 表示 SQL 执行的结果
 type ExecutionResult struct {
-	SQL       string
+	Statement models.Statement
+	SQL       string // 渲染了参数值的 SQL，用于展示和日志
 	Success   bool
 	Error     error
 	Duration  time.Duration
@@ -32,33 +34,39 @@ func NewExecutor(targetConn *database.Connection, logger *logger.Logger) *Execut
 }
 
 // ExecuteSQL 执行 SQL 语句列表
-func (e *Executor) ExecuteSQL(sqls []string) []ExecutionResult {
+func (e *Executor) ExecuteSQL(stmts []models.Statement) []ExecutionResult {
 	var results []ExecutionResult
 
-	for _, sql := range sqls {
-		result := e.executeSingleSQL(sql)
+	for _, stmt := range stmts {
+		result := e.executeSingleSQL(stmt)
 		results = append(results, result)
 
 		// 记录日志
 		if result.Success {
-			e.logger.Info(fmt.Sprintf("SQL executed successfully: %s (%.2fms)", sql, result.Duration.Seconds()*1000))
+			e.logger.Info(fmt.Sprintf("SQL executed successfully: %s (%.2fms)", result.SQL, result.Duration.Seconds()*1000))
 		} else {
-			e.logger.Error(fmt.Sprintf("SQL execution failed: %s, Error: %v", sql, result.Error))
+			e.logger.Error(fmt.Sprintf("SQL execution failed: %s, Error: %v", result.SQL, result.Error))
 		}
 	}
 
 	return results
 }
 
-// executeSingleSQL 执行单条 SQL 语句
-func (e *Executor) executeSingleSQL(sql string) ExecutionResult {
+// executeSingleSQL 执行单条 SQL 语句，参数通过占位符绑定
+func (e *Executor) executeSingleSQL(stmt models.Statement) ExecutionResult {
 	start := time.Now()
 
 	result := ExecutionResult{
-		SQL: sql,
+		Statement: stmt,
+		SQL:       RenderStatement(stmt),
 	}
 
-	_, err := e.targetConn.Exec(sql)
+	var err error
+	if stmt.TimeZone != "" {
+		_, err = e.targetConn.ExecWithTimeZone(stmt.TimeZone, stmt.SQL, stmt.Args...)
+	} else {
+		_, err = e.targetConn.Exec(stmt.SQL, stmt.Args...)
+	}
 	duration := time.Since(start)
 	result.Duration = duration
 
@@ -0,0 +1,155 @@
+package sync
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// FormatLiteral 将值渲染为 MySQL 字面量，仅用于展示和导出脚本，执行时使用参数绑定
+// - nil 渲染为 NULL
+// - 字符串转义反斜杠、引号、NUL 和控制字符
+// - 不是合法 UTF-8 的 []byte 渲染为十六进制字面量 X'...'
+// - time.Time 渲染为 MySQL 格式 'YYYY-MM-DD HH:MM:SS[.ffffff]'
+func FormatLiteral(val interface{}) string {
+	if val == nil {
+		return "NULL"
+	}
+
+	switch v := val.(type) {
+	case []byte:
+		if !utf8.Valid(v) {
+			return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+		}
+		return quoteString(string(v))
+	case string:
+		return quoteString(v)
+	case time.Time:
+		return "'" + formatDateTime(v) + "'"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return quoteString(fmt.Sprintf("%v", v))
+	}
+}
+
+// quoteString 将字符串转义为单引号字面量
+func quoteString(str string) string {
+	var sb strings.Builder
+	sb.Grow(len(str) + 2)
+	sb.WriteByte('\'')
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case 0:
+			sb.WriteString(`\0`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case 0x1a:
+			sb.WriteString(`\Z`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '"':
+			sb.WriteString(`\"`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// formatDateTime 将时间格式化为 MySQL 的 DATETIME 格式，仅在有小数秒时输出微秒部分
+func formatDateTime(t time.Time) string {
+	if t.Nanosecond() == 0 {
+		return t.Format("2006-01-02 15:04:05")
+	}
+	return t.Format("2006-01-02 15:04:05.000000")
+}
+
+// RenderStatement 将语句中的占位符替换为字面量，得到可直接阅读和执行的 SQL（含结尾分号）
+// 反引号标识符和单引号字符串中的 ? 不会被替换
+func RenderStatement(stmt models.Statement) string {
+	if len(stmt.Args) == 0 {
+		return stmt.SQL + ";"
+	}
+
+	var sb strings.Builder
+	argIndex := 0
+	var quote byte
+	for i := 0; i < len(stmt.SQL); i++ {
+		c := stmt.SQL[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '\'' && i+1 < len(stmt.SQL) {
+				sb.WriteByte(c)
+				i++
+				c = stmt.SQL[i]
+			}
+		case c == '`' || c == '\'':
+			quote = c
+		case c == '?' && argIndex < len(stmt.Args):
+			sb.WriteString(FormatLiteral(stmt.Args[argIndex]))
+			argIndex++
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	sb.WriteByte(';')
+	return sb.String()
+}
+
+// RenderStatements 渲染语句列表
+func RenderStatements(stmts []models.Statement) []string {
+	sqls := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		sqls[i] = RenderStatement(stmt)
+	}
+	return sqls
+}
+
+// scriptDelimiter 是脚本中复合语句使用的临时分隔符
+const scriptDelimiter = "$$"
+
+// WriteScript 将语句列表导出为可用 mysql 客户端执行的 SQL 脚本
+// 触发器等复合语句体内含有分号，使用 DELIMITER 包裹；指定了会话时区的语句前后切换并恢复时区
+func WriteScript(w io.Writer, stmts []models.Statement) error {
+	for _, stmt := range stmts {
+		sql := RenderStatement(stmt)
+		if stmt.IsCompound() {
+			sql = fmt.Sprintf("DELIMITER %s\n%s%s\nDELIMITER ;", scriptDelimiter, strings.TrimSuffix(sql, ";"), scriptDelimiter)
+		}
+		if stmt.TimeZone != "" {
+			sql = fmt.Sprintf("SET @saved_time_zone = @@session.time_zone;\nSET time_zone = %s;\n%s\nSET time_zone = @saved_time_zone;",
+				quoteString(stmt.TimeZone), sql)
+		}
+		if _, err := fmt.Fprintf(w, "-- %s %s\n%s\n\n", stmt.Kind, stmt.Object, sql); err != nil {
+			return fmt.Errorf("failed to write script: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestFormatLiteral(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "NULL"},
+		{"it's", `'it\'s'`},
+		{"a\\b", `'a\\b'`},
+		{"nul\x00ctl\n\x1a", `'nul\0ctl\n\Z'`},
+		{[]byte("abc"), "'abc'"},
+		{[]byte{0xff, 0x00, 0x01}, "X'FF0001'"},
+		{int64(42), "42"},
+		{1.5, "1.5"},
+		{true, "1"},
+		{time.Date(2026, 2, 7, 10, 30, 0, 0, time.UTC), "'2026-02-07 10:30:00'"},
+		{time.Date(2026, 2, 7, 10, 30, 0, 123000, time.UTC), "'2026-02-07 10:30:00.000123'"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatLiteral(tt.value); got != tt.expected {
+			t.Errorf("Expected FormatLiteral(%#v) to be %s, got %s", tt.value, tt.expected, got)
+		}
+	}
+}
+
+func TestRenderStatement(t *testing.T) {
+	stmt := models.Statement{
+		SQL:  "UPDATE `t` SET `why?` = ?, `note` = ? WHERE `id` = ?",
+		Args: []interface{}{"what?", nil, int64(7)},
+	}
+
+	expected := "UPDATE `t` SET `why?` = 'what?', `note` = NULL WHERE `id` = 7;"
+	if got := RenderStatement(stmt); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestWriteScriptDelimiter(t *testing.T) {
+	stmts := []models.Statement{
+		{Kind: models.StatementDropRoutine, Object: "procedure `p`", SQL: "DROP PROCEDURE IF EXISTS `p`"},
+		{Kind: models.StatementCreateRoutine, Object: "procedure `p`", SQL: "CREATE PROCEDURE `p`() BEGIN SELECT 1; END"},
+	}
+
+	var sb strings.Builder
+	if err := WriteScript(&sb, stmts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "-- DROP ROUTINE procedure `p`\nDROP PROCEDURE IF EXISTS `p`;\n\n" +
+		"-- CREATE ROUTINE procedure `p`\nDELIMITER $$\nCREATE PROCEDURE `p`() BEGIN SELECT 1; END$$\nDELIMITER ;\n\n"
+	if got := sb.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestWriteScriptTimeZone(t *testing.T) {
+	stmts := []models.Statement{
+		{Kind: models.StatementCreateEvent, Object: "event `ev`", TimeZone: "+08:00",
+			SQL: "CREATE EVENT `ev` ON SCHEDULE AT '2099-01-01 00:00:00' ON COMPLETION NOT PRESERVE ENABLE DO DELETE FROM `logs`"},
+	}
+
+	var sb strings.Builder
+	if err := WriteScript(&sb, stmts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "-- CREATE EVENT event `ev`\nSET @saved_time_zone = @@session.time_zone;\nSET time_zone = '+08:00';\n" +
+		"DELIMITER $$\nCREATE EVENT `ev` ON SCHEDULE AT '2099-01-01 00:00:00' ON COMPLETION NOT PRESERVE ENABLE DO DELETE FROM `logs`$$\nDELIMITER ;\n" +
+		"SET time_zone = @saved_time_zone;\n\n"
+	if got := sb.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"strings"
+
+	"github.com/yuhuo/sync-db/database"
+	"github.com/yuhuo/sync-db/models"
+)
+
+// schemaNormalizer 在比对前规范化两边的元数据，屏蔽服务端版本和类型（MySQL 5.7/8.0、MariaDB）带来的写法差异
+// 一边的服务端不支持的特性（如降序索引）在比对时忽略，只报告两边都能表达的差异
+type schemaNormalizer struct {
+	ignoreIndexOrder      bool // 有一边不支持降序索引
+	ignoreIndexVisibility bool // 有一边不支持不可见索引
+}
+
+// newSchemaNormalizer 根据源库和目标库的服务端版本创建规范化器
+func newSchemaNormalizer(source, target database.ServerVersion) schemaNormalizer {
+	return schemaNormalizer{
+		ignoreIndexOrder:      !source.SupportsDescendingIndexes() || !target.SupportsDescendingIndexes(),
+		ignoreIndexVisibility: !source.SupportsInvisibleIndexes() || !target.SupportsInvisibleIndexes(),
+	}
+}
+
+// column 返回用于比对的列定义
+func (n schemaNormalizer) column(col models.Column) models.Column {
+	// YEAR(4) 的显示宽度在 MySQL 8.0.19 起不再显示
+	if strings.EqualFold(col.Type, "year(4)") {
+		col.Type = "year"
+	}
+	col.Charset = normalizeCharsetName(col.Charset)
+	col.Collation = normalizeCharsetName(col.Collation)
+	return col
+}
+
+// index 返回用于比对的索引定义
+func (n schemaNormalizer) index(idx models.Index) models.Index {
+	if n.ignoreIndexVisibility {
+		idx.Visible = true
+	}
+	parts := make([]models.IndexPart, len(idx.Parts))
+	for i, part := range idx.Parts {
+		if n.ignoreIndexOrder {
+			part.Descending = false
+		}
+		if part.Expression != "" {
+			part.Expression = normalizeExpression(part.Expression)
+		}
+		parts[i] = part
+	}
+	idx.Parts = parts
+	return idx
+}
+
+// viewDefinition 返回用于比对的视图定义：统一空白和大小写，utf8mb3 统一写为 utf8（字符集前缀如 _utf8mb3'x'）
+func (n schemaNormalizer) viewDefinition(def string) string {
+	return strings.ReplaceAll(normalizeViewDefinition(def), "utf8mb3", "utf8")
+}
+
+// normalizeCharsetName 将 utf8 统一为 utf8mb3（MySQL 8.0.30 起以 utf8mb3 显示），包括排序规则名，如 utf8_general_ci
+func normalizeCharsetName(name *string) *string {
+	if name == nil {
+		return nil
+	}
+	lower := strings.ToLower(*name)
+	if lower == "utf8" || strings.HasPrefix(lower, "utf8_") {
+		normalized := "utf8mb3" + lower[len("utf8"):]
+		return &normalized
+	}
+	return name
+}
+
+// sameCollation 判断两个排序规则（或字符集）是否相同，utf8 与 utf8mb3 视为相同
+func sameCollation(c1, c2 string) bool {
+	return *normalizeCharsetName(&c1) == *normalizeCharsetName(&c2)
+}
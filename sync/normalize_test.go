@@ -0,0 +1,38 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/yuhuo/sync-db/database"
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestSchemaNormalizer(t *testing.T) {
+	mysql57 := database.ParseServerVersion("5.7.44-log")
+	mysql80 := database.ParseServerVersion("8.0.36")
+	mariadb := database.ParseServerVersion("5.5.5-10.6.16-MariaDB")
+	if !mariadb.IsMariaDB() || mariadb.String() != "MariaDB 10.6.16" {
+		t.Errorf("Expected MariaDB 10.6.16, got %s", mariadb)
+	}
+
+	desc := models.Index{Name: "idx_created", Type: "INDEX", Visible: true,
+		Parts: []models.IndexPart{{Column: "created_at", Descending: true}}}
+	asc := desc
+	asc.Parts = []models.IndexPart{{Column: "created_at"}}
+
+	n := newSchemaNormalizer(mysql80, mysql57)
+	if indexSignature(n.index(desc)) != indexSignature(n.index(asc)) {
+		t.Error("Expected index order to be ignored when one side is MySQL 5.7")
+	}
+	n = newSchemaNormalizer(mysql80, mysql80)
+	if indexSignature(n.index(desc)) == indexSignature(n.index(asc)) {
+		t.Error("Expected index order to be compared between MySQL 8.0 servers")
+	}
+
+	if !sameCollation("utf8_general_ci", "utf8mb3_general_ci") || sameCollation("utf8_general_ci", "utf8mb4_general_ci") {
+		t.Error("Expected utf8 to equal utf8mb3 only")
+	}
+	if n.viewDefinition("select _utf8mb3'a' AS `x`") != n.viewDefinition("SELECT  _utf8'a' AS `x`") {
+		t.Error("Expected view definitions to match after normalization")
+	}
+}
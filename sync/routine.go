@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// compareRoutines 比对存储过程和函数的差异
+// CREATE 语句包含参数、返回值、特性、SQL SECURITY 和语句体，忽略空白差异后整体比较
+func (c *Comparator) compareRoutines() ([]models.RoutineDifference, []models.SkippedRoutine, error) {
+	sourceRoutines, err := c.sourceQueryHelper.GetRoutines()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetRoutines, err := c.targetQueryHelper.GetRoutines()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	routineDiffs, skipped := diffRoutines(sourceRoutines, targetRoutines)
+	return routineDiffs, skipped, nil
+}
+
+// diffRoutines 比对两边的存储程序
+// 任一边没有权限查看定义的存储程序无法判断是否修改，也不能在目标库中创建或删除，整体跳过并报告
+func diffRoutines(sourceRoutines, targetRoutines []models.RoutineDefinition) ([]models.RoutineDifference, []models.SkippedRoutine) {
+	var skipped []models.SkippedRoutine
+	hidden := make(map[string]bool)
+	for _, routines := range []struct {
+		side     string
+		routines []models.RoutineDefinition
+	}{{"source", sourceRoutines}, {"target", targetRoutines}} {
+		for _, routine := range routines.routines {
+			if routine.Hidden && !hidden[routine.Key()] {
+				hidden[routine.Key()] = true
+				skipped = append(skipped, models.SkippedRoutine{
+					RoutineName: routine.RoutineName,
+					RoutineType: routine.RoutineType,
+					Reason:      fmt.Sprintf("no privilege to read its definition in %s database", routines.side),
+				})
+			}
+		}
+	}
+	sourceRoutines = withoutRoutines(sourceRoutines, hidden)
+	targetRoutines = withoutRoutines(targetRoutines, hidden)
+
+	sourceRoutineMap := make(map[string]models.RoutineDefinition)
+	for _, routine := range sourceRoutines {
+		sourceRoutineMap[routine.Key()] = routine
+	}
+
+	targetRoutineMap := make(map[string]models.RoutineDefinition)
+	for _, routine := range targetRoutines {
+		targetRoutineMap[routine.Key()] = routine
+	}
+
+	var routineDiffs []models.RoutineDifference
+
+	// 检查新增和修改的存储程序
+	for i := range sourceRoutines {
+		sourceRoutine := &sourceRoutines[i]
+		targetRoutine, exists := targetRoutineMap[sourceRoutine.Key()]
+		if !exists {
+			routineDiffs = append(routineDiffs, models.RoutineDifference{
+				RoutineName: sourceRoutine.RoutineName,
+				RoutineType: sourceRoutine.RoutineType,
+				Operation:   "CREATE",
+				NewRoutine:  sourceRoutine,
+			})
+		} else if normalizeWhitespace(sourceRoutine.CreateSQL) != normalizeWhitespace(targetRoutine.CreateSQL) {
+			routineDiffs = append(routineDiffs, models.RoutineDifference{
+				RoutineName: sourceRoutine.RoutineName,
+				RoutineType: sourceRoutine.RoutineType,
+				Operation:   "MODIFY",
+				OldRoutine:  &targetRoutine,
+				NewRoutine:  sourceRoutine,
+			})
+		}
+	}
+
+	// 检查删除的存储程序
+	for i := range targetRoutines {
+		targetRoutine := &targetRoutines[i]
+		if _, exists := sourceRoutineMap[targetRoutine.Key()]; !exists {
+			routineDiffs = append(routineDiffs, models.RoutineDifference{
+				RoutineName: targetRoutine.RoutineName,
+				RoutineType: targetRoutine.RoutineType,
+				Operation:   "DROP",
+				OldRoutine:  targetRoutine,
+			})
+		}
+	}
+
+	return routineDiffs, skipped
+}
+
+// withoutRoutines 返回去掉指定存储程序后的列表
+func withoutRoutines(routines []models.RoutineDefinition, excluded map[string]bool) []models.RoutineDefinition {
+	var result []models.RoutineDefinition
+	for _, routine := range routines {
+		if !excluded[routine.Key()] {
+			result = append(result, routine)
+		}
+	}
+	return result
+}
+
+// generateDropRoutineSQL 生成删除存储程序的语句（DROP 和 MODIFY 的存储程序）
+func generateDropRoutineSQL(routineDiffs []models.RoutineDifference) []models.Statement {
+	var stmts []models.Statement
+	for _, routineDiff := range routineDiffs {
+		if routineDiff.Operation == "DROP" || routineDiff.Operation == "MODIFY" {
+			stmts = append(stmts, models.Statement{
+				Kind:   models.StatementDropRoutine,
+				Table:  routineDiff.RoutineName,
+				Object: routineObject(routineDiff.RoutineType, routineDiff.RoutineName),
+				SQL:    fmt.Sprintf("DROP %s IF EXISTS `%s`", routineDiff.RoutineType, routineDiff.RoutineName),
+			})
+		}
+	}
+	return stmts
+}
+
+// generateCreateRoutineSQL 生成创建存储程序的语句（CREATE 和 MODIFY 的存储程序）
+func generateCreateRoutineSQL(routineDiffs []models.RoutineDifference) []models.Statement {
+	var stmts []models.Statement
+	for _, routineDiff := range routineDiffs {
+		if routineDiff.Operation == "CREATE" || routineDiff.Operation == "MODIFY" {
+			stmts = append(stmts, models.Statement{
+				Kind:   models.StatementCreateRoutine,
+				Table:  routineDiff.RoutineName,
+				Object: routineObject(routineDiff.RoutineType, routineDiff.RoutineName),
+				SQL:    routineDiff.NewRoutine.CreateSQL,
+			})
+		}
+	}
+	return stmts
+}
+
+// routineObject 返回语句中描述存储程序的对象名，如 "procedure `p_cleanup`"
+func routineObject(routineType, routineName string) string {
+	return fmt.Sprintf("%s `%s`", strings.ToLower(routineType), routineName)
+}
@@ -0,0 +1,28 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestDiffRoutinesSkipsHiddenDefinitions(t *testing.T) {
+	source := []models.RoutineDefinition{
+		{RoutineName: "p_report", RoutineType: models.RoutineProcedure, Hidden: true},
+		{RoutineName: "f_total", RoutineType: models.RoutineFunction, CreateSQL: "CREATE FUNCTION `f_total`() RETURNS int RETURN 1"},
+	}
+	target := []models.RoutineDefinition{
+		{RoutineName: "p_report", RoutineType: models.RoutineProcedure, CreateSQL: "CREATE PROCEDURE `p_report`() SELECT 1"},
+		{RoutineName: "p_cleanup", RoutineType: models.RoutineProcedure, Hidden: true},
+	}
+
+	diffs, skipped := diffRoutines(source, target)
+
+	// 没有权限查看定义的存储程序既不修改也不删除
+	if len(diffs) != 1 || diffs[0].RoutineName != "f_total" || diffs[0].Operation != "CREATE" {
+		t.Errorf("Expected only f_total to be created, got %+v", diffs)
+	}
+	if len(skipped) != 2 || skipped[0].RoutineName != "p_report" || skipped[1].RoutineName != "p_cleanup" {
+		t.Errorf("Expected p_report and p_cleanup to be skipped, got %+v", skipped)
+	}
+}
@@ -2,8 +2,11 @@ package sync
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/yuhuo/sync-db/config"
 	"github.com/yuhuo/sync-db/database"
 	"github.com/yuhuo/sync-db/models"
 )
@@ -11,59 +14,166 @@ import (
 // SQLGenerator 用于生成 SQL 语句
 type SQLGenerator struct {
 	sourceQueryHelper *database.QueryHelper
+	targetVersion     database.ServerVersion // 生成的语句只使用目标库支持的语法
+	options           config.GenerateConfig
 }
 
 // NewSQLGenerator 创建 SQL 生成器
-func NewSQLGenerator(sourceConn *database.Connection) *SQLGenerator {
+func NewSQLGenerator(sourceConn, targetConn *database.Connection, options config.GenerateConfig) *SQLGenerator {
 	return &SQLGenerator{
 		sourceQueryHelper: database.NewQueryHelper(sourceConn),
+		targetVersion:     targetConn.Version(),
+		options:           options,
 	}
 }
 
 // GenerateSQL 根据差异生成 SQL 语句
-func (sg *SQLGenerator) GenerateSQL(diff *models.SyncDifference) ([]string, error) {
-	var sqls []string
+func (sg *SQLGenerator) GenerateSQL(diff *models.SyncDifference) ([]models.Statement, error) {
+	var stmts []models.Statement
 
 	// 1. 先删除视图（因为可能有依赖关系）
 	for _, viewDiff := range diff.ViewDifferences {
 		if viewDiff.Operation == "DROP" || viewDiff.Operation == "MODIFY" {
-			sql := fmt.Sprintf("DROP VIEW IF EXISTS `%s`;", viewDiff.ViewName)
-			sqls = append(sqls, sql)
+			stmts = append(stmts, models.Statement{
+				Kind:   models.StatementDropView,
+				Table:  viewDiff.ViewName,
+				Object: fmt.Sprintf("view `%s`", viewDiff.ViewName),
+				SQL:    fmt.Sprintf("DROP VIEW IF EXISTS `%s`", viewDiff.ViewName),
+			})
 		}
 	}
 
-	// 2. 修改表结构
+	// 2. 删除触发器、存储程序和定时事件（在修改表结构之前，避免触发器引用的列被修改）
+	stmts = append(stmts, generateDropTriggerSQL(diff.TriggerDifferences)...)
+	stmts = append(stmts, generateDropRoutineSQL(diff.RoutineDifferences)...)
+	stmts = append(stmts, generateDropEventSQL(diff.EventDifferences)...)
+
+	// 3. 表改名（在其余结构修改之前，后续语句都使用新表名）
+	for _, rename := range diff.TableRenames {
+		if !rename.Confirmed {
+			continue
+		}
+		stmts = append(stmts, models.Statement{
+			Kind:   models.StatementRenameTable,
+			Table:  rename.NewName,
+			Object: fmt.Sprintf("table `%s`", rename.OldName),
+			SQL:    fmt.Sprintf("RENAME TABLE `%s` TO `%s`", rename.OldName, rename.NewName),
+		})
+	}
+
+	// 4. 删除外键（在修改列和删除索引之前，避免被外键依赖阻止）
 	for _, structDiff := range diff.StructureDifferences {
-		structSQLs, err := sg.generateStructureSQL(structDiff)
+		for _, fk := range structDiff.ForeignKeysDeleted {
+			stmts = append(stmts, alterTableStatement(structDiff.TableName, "foreign key `"+fk.Name+"`",
+				fmt.Sprintf("ALTER TABLE `%s` DROP FOREIGN KEY `%s`", structDiff.TableName, fk.Name)))
+		}
+	}
+
+	// 5. 修改表结构（新表按外键依赖排序，被引用的表先创建）
+	for _, structDiff := range orderByForeignKeyDependency(diff.StructureDifferences) {
+		structStmts, err := sg.generateStructureSQL(structDiff)
 		if err != nil {
 			return nil, err
 		}
-		sqls = append(sqls, structSQLs...)
+		stmts = append(stmts, structStmts...)
 	}
 
-	// 3. 创建新视图
+	// 6. 新增外键（在所有表和索引就绪之后，外键可以使用已创建的索引）
+	for _, structDiff := range diff.StructureDifferences {
+		if structDiff.IsNewTable {
+			continue // 新表的外键已包含在 CREATE TABLE 中
+		}
+		for _, fk := range structDiff.ForeignKeysAdded {
+			stmts = append(stmts, alterTableStatement(structDiff.TableName, "foreign key `"+fk.Name+"`",
+				fmt.Sprintf("ALTER TABLE `%s` ADD %s", structDiff.TableName, buildForeignKeyDefinition(fk))))
+		}
+	}
+
+	// 7. 创建存储程序（在表结构修改之后，先于可能调用它的事件、视图和触发器创建）
+	stmts = append(stmts, generateCreateRoutineSQL(diff.RoutineDifferences)...)
+
+	// 8. 创建和修改定时事件（事件可能调用存储程序）
+	stmts = append(stmts, sg.generateEventSQL(diff.EventDifferences)...)
+
+	// 9. 创建新视图
 	for _, viewDiff := range diff.ViewDifferences {
 		if viewDiff.Operation == "CREATE" || viewDiff.Operation == "MODIFY" {
-			sql := fmt.Sprintf("CREATE VIEW `%s` AS %s;", viewDiff.ViewName, viewDiff.NewDefinition)
-			sqls = append(sqls, sql)
+			stmts = append(stmts, models.Statement{
+				Kind:   models.StatementCreateView,
+				Table:  viewDiff.ViewName,
+				Object: fmt.Sprintf("view `%s`", viewDiff.ViewName),
+				SQL:    fmt.Sprintf("CREATE VIEW `%s` AS %s", viewDiff.ViewName, viewDiff.NewDefinition),
+			})
 		}
 	}
 
-	// 4. 修改表数据
+	// 10. 修改表数据
 	for _, dataDiff := range diff.DataDifferences {
-		dataSQLs, err := sg.generateDataSQL(dataDiff)
+		dataStmts, err := sg.generateDataSQL(dataDiff)
 		if err != nil {
 			return nil, err
 		}
-		sqls = append(sqls, dataSQLs...)
+		stmts = append(stmts, dataStmts...)
 	}
 
-	return sqls, nil
+	// 11. 创建触发器（在修改表数据之后，同步的数据不会再次触发目标库的触发器）
+	if len(diff.TriggerDifferences) > 0 {
+		sourceTriggers, err := sg.sourceQueryHelper.GetTriggers()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, generateCreateTriggerSQL(diff.TriggerDifferences, sourceTriggers)...)
+	}
+
+	// 12. 最后处理目标库独有的表（按 orphan_tables 策略删除或改名归档）
+	stmts = append(stmts, generateOrphanTableSQL(diff.OrphanTables, time.Now())...)
+
+	return stmts, nil
+}
+
+// generateOrphanTableSQL 生成删除或归档目标库独有的表的语句
+// 删除的表合并为一条 DROP TABLE，避免这些表之间的外键决定删除顺序；归档的表改名为 <表名>_archived_<时间戳>
+func generateOrphanTableSQL(orphans []models.OrphanTable, now time.Time) []models.Statement {
+	var stmts []models.Statement
+	var dropTables []string
+	suffix := "_archived_" + now.Format("20060102150405")
+
+	for _, orphan := range orphans {
+		switch orphan.Action {
+		case models.OrphanDrop:
+			dropTables = append(dropTables, orphan.TableName)
+		case models.OrphanArchive:
+			// 表名最长 64 个字符，超出时截断原表名
+			archiveName := orphan.TableName
+			if len(archiveName)+len(suffix) > 64 {
+				archiveName = archiveName[:64-len(suffix)]
+			}
+			archiveName += suffix
+			stmts = append(stmts, models.Statement{
+				Kind:   models.StatementRenameTable,
+				Table:  orphan.TableName,
+				Object: fmt.Sprintf("table `%s`", orphan.TableName),
+				SQL:    fmt.Sprintf("RENAME TABLE `%s` TO `%s`", orphan.TableName, archiveName),
+			})
+		}
+	}
+
+	if len(dropTables) > 0 {
+		quoted := "`" + strings.Join(dropTables, "`, `") + "`"
+		stmts = append(stmts, models.Statement{
+			Kind:   models.StatementDropTable,
+			Table:  strings.Join(dropTables, ", "),
+			Object: "table " + quoted,
+			SQL:    "DROP TABLE IF EXISTS " + quoted,
+		})
+	}
+
+	return stmts
 }
 
 // generateStructureSQL 生成表结构修改 SQL
-func (sg *SQLGenerator) generateStructureSQL(structDiff models.StructureDifference) ([]string, error) {
-	var sqls []string
+func (sg *SQLGenerator) generateStructureSQL(structDiff models.StructureDifference) ([]models.Statement, error) {
+	var stmts []models.Statement
 
 	tableName := structDiff.TableName
 
@@ -73,152 +183,629 @@ func (sg *SQLGenerator) generateStructureSQL(structDiff models.StructureDifferen
 		if err != nil {
 			return nil, err
 		}
-		sqls = append(sqls, createSQL)
-		return sqls, nil // 新表已创建，不需要后续的 ALTER TABLE
+		stmts = append(stmts, models.Statement{
+			Kind:   models.StatementCreateTable,
+			Table:  tableName,
+			Object: fmt.Sprintf("table `%s`", tableName),
+			SQL:    createSQL,
+		})
+		return stmts, nil // 新表已创建，不需要后续的 ALTER TABLE
+	}
+
+	// 目标库现有数据不满足或无法验证的约束不生成语句，保留目标库中原有的同名约束
+	violated := make(map[string]bool)
+	for _, violation := range structDiff.CheckViolations {
+		violated[violation.Constraint.Name] = true
+	}
+
+	// 移除分区（在修改列和索引之前，避免分区列必须包含在唯一索引中的限制）
+	partitionChange := structDiff.PartitionChange
+	if partitionChange != nil && partitionChange.Repartition && partitionChange.NewPartitioning == nil {
+		stmts = append(stmts, alterTableStatement(tableName, "partitioning",
+			fmt.Sprintf("ALTER TABLE `%s` REMOVE PARTITIONING", tableName)))
+	}
+
+	// 删除 CHECK 约束（在修改和删除列之前，避免约束引用的列无法删除或修改）
+	for _, check := range structDiff.ChecksDeleted {
+		if violated[check.Name] {
+			continue
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "check `"+check.Name+"`",
+			fmt.Sprintf("ALTER TABLE `%s` DROP CHECK `%s`", tableName, check.Name)))
+	}
+
+	// 两边都存在的列
+	isAdded := make(map[string]bool)
+	for _, col := range structDiff.ColumnsAdded {
+		isAdded[col.Name] = true
+	}
+	isCommon := func(name string) bool { return !isAdded[name] }
+
+	// 表选项：在列和索引修改之前，后续修改在新的存储引擎和字符集下进行
+	stmts = append(stmts, generateTableOptionSQL(tableName, structDiff.OptionsModified)...)
+
+	// 列改名（仅已确认的改名）：定义未变时使用 RENAME COLUMN，否则使用 CHANGE COLUMN 同时修改定义
+	// 目标库不支持 RENAME COLUMN 时（MySQL 5.7、MariaDB 10.5.2 之前）同样使用 CHANGE COLUMN
+	for _, rename := range structDiff.ConfirmedColumnRenames() {
+		renamed := rename.OldColumn
+		renamed.Name = rename.NewName
+		sql := fmt.Sprintf("ALTER TABLE `%s` RENAME COLUMN `%s` TO `%s`", tableName, rename.OldName, rename.NewName)
+		if !sg.targetVersion.SupportsRenameColumn() || !columnsEqual(renamed, rename.NewColumn) {
+			sql = fmt.Sprintf("ALTER TABLE `%s` CHANGE COLUMN `%s` %s", tableName, rename.OldName, sg.buildColumnDefinition(rename.NewColumn))
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "column `"+rename.NewName+"`", sql))
 	}
 
-	// 新增列 - 使用完整的列定义
+	// 调整列顺序（需要开启 ReorderColumns）：在新增列之前完成，使新增列能按源库位置插入
+	if sg.options.ReorderColumns {
+		for _, col := range structDiff.ColumnsReordered {
+			colDef := sg.buildColumnDefinition(col)
+			stmts = append(stmts, alterTableStatement(tableName, "column `"+col.Name+"` position",
+				fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN %s%s", tableName, colDef,
+					columnPosition(structDiff.ColumnOrder, col.Name, isCommon))))
+		}
+	}
+
+	// 新增列 - 使用完整的列定义，按源库中的位置插入（按源库顺序新增，前面的新增列已存在）
 	for _, col := range structDiff.ColumnsAdded {
 		colDef := sg.buildColumnDefinition(col)
-		sql := fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s;", tableName, colDef)
-		sqls = append(sqls, sql)
+		position := ""
+		if !appendsAtEnd(structDiff.ColumnOrder, col.Name, isCommon) {
+			position = columnPosition(structDiff.ColumnOrder, col.Name, func(string) bool { return true })
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "column `"+col.Name+"`",
+			fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s%s", tableName, colDef, position)))
 	}
 
 	// 删除列
 	for _, colName := range structDiff.ColumnsDeleted {
-		sql := fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`;", tableName, colName)
-		sqls = append(sqls, sql)
+		stmts = append(stmts, alterTableStatement(tableName, "column `"+colName+"`",
+			fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`", tableName, colName)))
 	}
 
 	// 修改列 - 使用新列的完整定义
 	for _, colMod := range structDiff.ColumnsModified {
 		colDef := sg.buildColumnDefinition(colMod.NewColumn)
-		sql := fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN %s;", tableName, colDef)
-		sqls = append(sqls, sql)
+		if needsColumnRebuild(colMod.OldColumn, colMod.NewColumn) {
+			// MODIFY 不能改变生成列的存储方式，也不能在虚拟列和普通列之间转换，需要删除后重建
+			stmts = append(stmts, alterTableStatement(tableName, "column `"+colMod.ColumnName+"`",
+				fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`, ADD COLUMN %s", tableName, colMod.ColumnName, colDef)))
+			continue
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "column `"+colMod.ColumnName+"`",
+			fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN %s", tableName, colDef)))
+	}
+
+	// 字符集或排序规则不同的列；表级 CONVERT TO 已转换全部字符列时不再逐列修改
+	if !convertsTable(structDiff.OptionsModified) {
+		for _, change := range structDiff.CharsetChanges {
+			stmts = append(stmts, alterTableStatement(tableName, "column `"+change.ColumnName+"` charset",
+				fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN %s", tableName, sg.buildColumnDefinition(change.NewColumn))))
+		}
 	}
 
 	// 删除索引
 	for _, idx := range structDiff.IndexesDeleted {
 		if idx.Type == "PRIMARY" {
-			sql := fmt.Sprintf("ALTER TABLE `%s` DROP PRIMARY KEY;", tableName)
-			sqls = append(sqls, sql)
+			stmts = append(stmts, alterTableStatement(tableName, "primary key",
+				fmt.Sprintf("ALTER TABLE `%s` DROP PRIMARY KEY", tableName)))
 		} else {
-			sql := fmt.Sprintf("ALTER TABLE `%s` DROP INDEX `%s`;", tableName, idx.Name)
-			sqls = append(sqls, sql)
+			stmts = append(stmts, alterTableStatement(tableName, "index `"+idx.Name+"`",
+				fmt.Sprintf("ALTER TABLE `%s` DROP INDEX `%s`", tableName, idx.Name)))
+		}
+	}
+
+	// 改名索引：目标库不支持 RENAME INDEX 时（MariaDB 10.5.2 之前）在同一条语句中删除旧索引并按新名称重建
+	for _, rename := range structDiff.IndexesRenamed {
+		sql := fmt.Sprintf("ALTER TABLE `%s` RENAME INDEX `%s` TO `%s`", tableName, rename.OldName, rename.NewName)
+		if !sg.targetVersion.SupportsRenameIndex() {
+			sql = fmt.Sprintf("ALTER TABLE `%s` DROP INDEX `%s`, ADD %s", tableName, rename.OldName, sg.buildTargetIndexDefinition(rename.Index))
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "index `"+rename.NewName+"`", sql))
+	}
+
+	// 定义变化的索引：在同一条 ALTER TABLE 中删除并重建，避免中间状态缺少索引或唯一约束
+	for _, mod := range structDiff.IndexesModified {
+		dropClause := fmt.Sprintf("DROP INDEX `%s`", mod.OldIndex.Name)
+		if mod.OldIndex.Type == "PRIMARY" {
+			dropClause = "DROP PRIMARY KEY"
 		}
+		stmts = append(stmts, alterTableStatement(tableName, "index `"+mod.IndexName+"`",
+			fmt.Sprintf("ALTER TABLE `%s` %s, ADD %s", tableName, dropClause, sg.buildTargetIndexDefinition(mod.NewIndex))))
 	}
 
 	// 新增索引
 	for _, idx := range structDiff.IndexesAdded {
-		sql := sg.generateAddIndexSQL(tableName, idx)
-		sqls = append(sqls, sql)
+		stmts = append(stmts, alterTableStatement(tableName, "index `"+idx.Name+"`",
+			sg.generateAddIndexSQL(tableName, idx)))
+	}
+
+	// 新增 CHECK 约束（在列修改完成之后）
+	for _, check := range structDiff.ChecksAdded {
+		if violated[check.Name] {
+			continue
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "check `"+check.Name+"`",
+			fmt.Sprintf("ALTER TABLE `%s` ADD %s", tableName, buildCheckDefinition(check))))
+	}
+
+	// 分区修改（在索引修改完成之后，唯一索引需要包含分区列）
+	stmts = append(stmts, sg.generatePartitionSQL(tableName, partitionChange)...)
+
+	return stmts, nil
+}
+
+// generatePartitionSQL 生成分区修改语句，顺序为删除、重组、新增
+// 删除分区会同时删除其中的数据，需要开启 DropPartitions，否则只在差异汇总中报告
+func (sg *SQLGenerator) generatePartitionSQL(tableName string, change *models.PartitionDifference) []models.Statement {
+	if change == nil {
+		return nil
 	}
 
-	return sqls, nil
+	partitioning := change.NewPartitioning
+	if change.Repartition {
+		if partitioning == nil {
+			return nil // 已在修改列之前移除分区
+		}
+		return []models.Statement{alterTableStatement(tableName, "partitioning",
+			fmt.Sprintf("ALTER TABLE `%s` %s", tableName, partitioning.Clause()))}
+	}
+
+	var stmts []models.Statement
+	if len(change.PartitionsDropped) > 0 && sg.options.DropPartitions {
+		names := make([]string, len(change.PartitionsDropped))
+		for i, part := range change.PartitionsDropped {
+			names[i] = part.Name
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "partition `"+strings.Join(names, "`, `")+"`",
+			fmt.Sprintf("ALTER TABLE `%s` DROP PARTITION `%s`", tableName, strings.Join(names, "`, `"))))
+	}
+
+	for _, reorganize := range change.PartitionsReorganized {
+		defs := make([]string, len(reorganize.Into))
+		for i, part := range reorganize.Into {
+			defs[i] = partitioning.PartitionDefinition(part)
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "partition `"+strings.Join(reorganize.From, "`, `")+"`",
+			fmt.Sprintf("ALTER TABLE `%s` REORGANIZE PARTITION `%s` INTO (%s)", tableName,
+				strings.Join(reorganize.From, "`, `"), strings.Join(defs, ", "))))
+	}
+
+	if len(change.PartitionsAdded) > 0 {
+		defs := make([]string, len(change.PartitionsAdded))
+		names := make([]string, len(change.PartitionsAdded))
+		for i, part := range change.PartitionsAdded {
+			defs[i] = partitioning.PartitionDefinition(part)
+			names[i] = part.Name
+		}
+		stmts = append(stmts, alterTableStatement(tableName, "partition `"+strings.Join(names, "`, `")+"`",
+			fmt.Sprintf("ALTER TABLE `%s` ADD PARTITION (%s)", tableName, strings.Join(defs, ", "))))
+	}
+
+	return stmts
+}
+
+// needsColumnRebuild 判断列修改是否无法通过 MODIFY COLUMN 完成
+// 涉及 VIRTUAL 生成列的存储方式变化只能删除后重建；STORED 生成列可以与普通列直接转换
+func needsColumnRebuild(oldCol, newCol models.Column) bool {
+	if oldCol.GeneratedStorage == newCol.GeneratedStorage {
+		return false
+	}
+	return oldCol.GeneratedStorage == "VIRTUAL" || newCol.GeneratedStorage == "VIRTUAL"
+}
+
+// buildCheckDefinition 构建 CHECK 约束定义，如 "CONSTRAINT `chk_price` CHECK (`price` > 0)"
+func buildCheckDefinition(check models.CheckConstraint) string {
+	def := fmt.Sprintf("CONSTRAINT `%s` CHECK (%s)", check.Name, normalizeExpression(check.Expression))
+	if !check.Enforced {
+		def += " NOT ENFORCED"
+	}
+	return def
+}
+
+// generateTableOptionSQL 生成修改表选项的语句
+// 转换字符集单独成一条语句，其余选项合并为一条 ALTER TABLE
+func generateTableOptionSQL(tableName string, changes []models.TableOptionChange) []models.Statement {
+	var stmts []models.Statement
+	var options []string
+	rebuild := false
+
+	for _, change := range changes {
+		switch change.Option {
+		case "CONVERT TO CHARACTER SET", "DEFAULT CHARACTER SET":
+			sql := fmt.Sprintf("ALTER TABLE `%s` %s %s COLLATE %s", tableName, change.Option,
+				models.CharsetOfCollation(change.NewValue), change.NewValue)
+			stmts = append(stmts, alterTableStatement(tableName, "table charset", sql))
+		case "COMMENT", "COMPRESSION":
+			options = append(options, fmt.Sprintf("%s = %s", change.Option, quoteString(tableOptionValue(change))))
+			rebuild = rebuild || change.Rebuild
+		default:
+			options = append(options, fmt.Sprintf("%s = %s", change.Option, tableOptionValue(change)))
+			rebuild = rebuild || change.Rebuild
+		}
+	}
+
+	if len(options) > 0 {
+		object := "table options"
+		if rebuild {
+			object = "table options (rebuilds table)"
+		}
+		stmts = append(stmts, alterTableStatement(tableName, object,
+			fmt.Sprintf("ALTER TABLE `%s` %s", tableName, strings.Join(options, " "))))
+	}
+
+	return stmts
+}
+
+// convertsTable 判断表选项修改中是否包含 CONVERT TO CHARACTER SET
+func convertsTable(changes []models.TableOptionChange) bool {
+	for _, change := range changes {
+		if change.Option == "CONVERT TO CHARACTER SET" {
+			return true
+		}
+	}
+	return false
+}
+
+// tableOptionValue 返回选项的新值，清除选项时使用对应的默认值
+func tableOptionValue(change models.TableOptionChange) string {
+	if change.NewValue == "" && change.Option == "COMPRESSION" {
+		return "None"
+	}
+	return change.NewValue
+}
+
+// columnPosition 返回列在源库顺序中的位置子句：紧跟在前面第一个满足 exists 的列之后，没有时为 FIRST
+func columnPosition(order []string, name string, exists func(string) bool) string {
+	for i, colName := range order {
+		if colName != name {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if exists(order[j]) {
+				return fmt.Sprintf(" AFTER `%s`", order[j])
+			}
+		}
+		return " FIRST"
+	}
+	return ""
+}
+
+// appendsAtEnd 判断列在源库顺序中是否排在所有已存在的列之后，此时新增列无需指定位置
+func appendsAtEnd(order []string, name string, exists func(string) bool) bool {
+	found := false
+	for _, colName := range order {
+		if colName == name {
+			found = true
+		} else if found && exists(colName) {
+			return false
+		}
+	}
+	return true
+}
+
+// alterTableStatement 构建一条 ALTER TABLE 语句
+func alterTableStatement(tableName, object, sql string) models.Statement {
+	return models.Statement{
+		Kind:   models.StatementAlterTable,
+		Table:  tableName,
+		Object: object,
+		SQL:    sql,
+	}
 }
 
 // generateAddIndexSQL 生成添加索引的 SQL
 func (sg *SQLGenerator) generateAddIndexSQL(tableName string, idx models.Index) string {
-	cols := strings.Join(idx.Columns, "`, `")
-	cols = "`" + cols + "`"
+	return fmt.Sprintf("ALTER TABLE `%s` ADD %s", tableName, sg.buildTargetIndexDefinition(idx))
+}
+
+// buildIndexDefinition 构建索引定义，还原索引类型、前缀长度、排序方向、函数表达式、
+// 索引方法、注释和可见性，如 "UNIQUE KEY `uk_name` (`name`(20) DESC) COMMENT '...'"
+func buildIndexDefinition(idx models.Index) string {
+	var sb strings.Builder
 
 	switch idx.Type {
 	case "PRIMARY":
-		return fmt.Sprintf("ALTER TABLE `%s` ADD PRIMARY KEY (%s);", tableName, cols)
+		sb.WriteString("PRIMARY KEY")
 	case "UNIQUE":
-		return fmt.Sprintf("ALTER TABLE `%s` ADD UNIQUE KEY `%s` (%s);", tableName, idx.Name, cols)
+		sb.WriteString("UNIQUE KEY `" + idx.Name + "`")
+	case "FULLTEXT":
+		sb.WriteString("FULLTEXT KEY `" + idx.Name + "`")
+	case "SPATIAL":
+		sb.WriteString("SPATIAL KEY `" + idx.Name + "`")
 	default:
-		return fmt.Sprintf("ALTER TABLE `%s` ADD INDEX `%s` (%s);", tableName, idx.Name, cols)
+		sb.WriteString("INDEX `" + idx.Name + "`")
+	}
+
+	sb.WriteString(" (" + buildIndexParts(idx) + ")")
+
+	// InnoDB 只支持 BTREE，仅在显式使用 HASH（如 MEMORY 表）时输出
+	if idx.Method == "HASH" {
+		sb.WriteString(" USING HASH")
+	}
+	if idx.Comment != "" {
+		sb.WriteString(" COMMENT " + FormatLiteral(idx.Comment))
 	}
+	if !idx.Visible && idx.Type != "PRIMARY" {
+		sb.WriteString(" INVISIBLE")
+	}
+
+	return sb.String()
+}
+
+// buildTargetIndexDefinition 按目标库支持的语法构建索引定义
+// 不支持降序索引时省略 DESC，不支持不可见索引时省略 INVISIBLE，MariaDB 的不可见索引写作 IGNORED
+func (sg *SQLGenerator) buildTargetIndexDefinition(idx models.Index) string {
+	if !sg.targetVersion.SupportsDescendingIndexes() {
+		parts := make([]models.IndexPart, len(idx.Parts))
+		for i, part := range idx.Parts {
+			part.Descending = false
+			parts[i] = part
+		}
+		idx.Parts = parts
+	}
+	if !sg.targetVersion.SupportsInvisibleIndexes() {
+		idx.Visible = true
+	}
+
+	def := buildIndexDefinition(idx)
+	if sg.targetVersion.IsMariaDB() && strings.HasSuffix(def, " INVISIBLE") {
+		def = strings.TrimSuffix(def, " INVISIBLE") + " IGNORED"
+	}
+	return def
+}
+
+// buildIndexParts 构建索引的列列表
+func buildIndexParts(idx models.Index) string {
+	// 没有明细时（如手工构造的索引）退化为按列名生成
+	if len(idx.Parts) == 0 {
+		return "`" + strings.Join(idx.Columns, "`, `") + "`"
+	}
+
+	parts := make([]string, len(idx.Parts))
+	for i, part := range idx.Parts {
+		if part.Expression != "" {
+			parts[i] = "(" + part.Expression + ")"
+		} else {
+			parts[i] = "`" + part.Column + "`"
+			if part.SubPart > 0 {
+				parts[i] += fmt.Sprintf("(%d)", part.SubPart)
+			}
+		}
+		if part.Descending {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildForeignKeyDefinition 构建外键定义，如
+// "CONSTRAINT `fk_name` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE RESTRICT"
+func buildForeignKeyDefinition(fk models.ForeignKey) string {
+	refTable := "`" + fk.ReferencedTable + "`"
+	if fk.ReferencedSchema != "" {
+		refTable = "`" + fk.ReferencedSchema + "`." + refTable
+	}
+
+	return fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES %s (`%s`) ON DELETE %s ON UPDATE %s",
+		fk.Name,
+		strings.Join(fk.Columns, "`, `"),
+		refTable,
+		strings.Join(fk.ReferencedColumns, "`, `"),
+		fk.OnDelete,
+		fk.OnUpdate,
+	)
+}
+
+// orderByForeignKeyDependency 调整结构差异的顺序：已有表保持原顺序，
+// 新表按外键依赖排序，使 CREATE TABLE 时被引用的新表已经存在
+func orderByForeignKeyDependency(structDiffs []models.StructureDifference) []models.StructureDifference {
+	var ordered []models.StructureDifference
+	newTables := make(map[string]models.StructureDifference)
+	var newTableOrder []string
+
+	for _, sd := range structDiffs {
+		if sd.IsNewTable && sd.TableDefinition != nil {
+			newTables[sd.TableName] = sd
+			newTableOrder = append(newTableOrder, sd.TableName)
+		} else {
+			ordered = append(ordered, sd)
+		}
+	}
+
+	visited := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true // 先标记，循环引用时按原顺序创建
+		sd := newTables[name]
+		for _, fk := range sd.TableDefinition.ForeignKeys {
+			if _, isNew := newTables[fk.ReferencedTable]; isNew && fk.ReferencedSchema == "" {
+				visit(fk.ReferencedTable)
+			}
+		}
+		ordered = append(ordered, sd)
+	}
+	for _, name := range newTableOrder {
+		visit(name)
+	}
+
+	return ordered
 }
 
 // generateDataSQL 生成表数据修改 SQL
-func (sg *SQLGenerator) generateDataSQL(dataDiff models.DataDifference) ([]string, error) {
-	var sqls []string
+func (sg *SQLGenerator) generateDataSQL(dataDiff models.DataDifference) ([]models.Statement, error) {
+	var stmts []models.Statement
 
 	tableName := dataDiff.TableName
-	pkColumn := dataDiff.PrimaryKeyName
+	keyColumns := dataDiff.KeyColumns
 
-	// 插入新增行
-	if len(dataDiff.RowsToInsert) > 0 {
-		insertSQLs := sg.generateInsertSQL(tableName, dataDiff.RowsToInsert)
-		sqls = append(sqls, insertSQLs...)
+	// 生成列不可写入
+	skipColumns := make(map[string]bool)
+	for _, col := range dataDiff.GeneratedColumns {
+		skipColumns[col] = true
+	}
+
+	// 删除行（在插入之前：键值仅大小写或尾部空格不同时，按排序规则与新行冲突的旧行需要先删除）
+	if len(dataDiff.RowsToDelete) > 0 {
+		var deleteStmts []models.Statement
+		if dataDiff.MatchMode == models.MatchByRowHash {
+			deleteStmts = sg.generateDeleteByRowSQL(tableName, keyColumns, dataDiff.RowsToDelete)
+		} else {
+			deleteStmts = sg.generateDeleteSQL(tableName, keyColumns, dataDiff.RowsToDelete)
+		}
+		stmts = append(stmts, deleteStmts...)
 	}
 
 	// 更新修改行
 	for _, updateRow := range dataDiff.RowsToUpdate {
-		sql := sg.generateUpdateSQL(tableName, pkColumn, updateRow)
-		sqls = append(sqls, sql)
+		if stmt, ok := sg.generateUpdateSQL(tableName, keyColumns, updateRow, skipColumns); ok {
+			stmts = append(stmts, stmt)
+		}
 	}
 
-	// 删除行
-	if len(dataDiff.RowsToDelete) > 0 {
-		deleteSQLs := sg.generateDeleteSQL(tableName, pkColumn, dataDiff.RowsToDelete)
-		sqls = append(sqls, deleteSQLs...)
+	// 插入新增行
+	if len(dataDiff.RowsToInsert) > 0 {
+		insertStmts := sg.generateInsertSQL(tableName, keyColumns, dataDiff.RowsToInsert, skipColumns)
+		stmts = append(stmts, insertStmts...)
 	}
 
-	return sqls, nil
+	return stmts, nil
 }
 
 // generateInsertSQL 生成 INSERT SQL（单行）
-func (sg *SQLGenerator) generateInsertSQL(tableName string, rows []map[string]interface{}) []string {
+func (sg *SQLGenerator) generateInsertSQL(tableName string, keyColumns []string, rows []map[string]interface{}, skipColumns map[string]bool) []models.Statement {
 	// 简化处理：每行单独生成一条 INSERT 语句
 	// 实际可以批量生成以提高效率
-	var sqls []string
+	var stmts []models.Statement
 
 	for _, row := range rows {
 		var columns []string
-		var values []interface{}
-
-		for col, val := range row {
-			columns = append(columns, col)
-			values = append(values, val)
+		for col := range row {
+			if !skipColumns[col] {
+				columns = append(columns, col)
+			}
 		}
+		sort.Strings(columns) // 保证生成的 SQL 和导出的脚本稳定
 
-		colStr := "`" + strings.Join(columns, "`, `") + "`"
-		valStr := ""
-		for i, val := range values {
-			if i > 0 {
-				valStr += ", "
-			}
-			valStr += sg.escapeValue(val)
+		values := make([]interface{}, len(columns))
+		for i, col := range columns {
+			values[i] = row[col]
 		}
 
-		sql := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s);", tableName, colStr, valStr)
-		sqls = append(sqls, sql)
+		colStr := "`" + strings.Join(columns, "`, `") + "`"
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+
+		stmts = append(stmts, models.Statement{
+			Kind:   models.StatementInsert,
+			Table:  tableName,
+			Object: describeRow(keyColumns, models.RowKeyOf(row, keyColumns)),
+			SQL:    fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s)", tableName, colStr, placeholders),
+			Args:   values,
+		})
 	}
 
-	return sqls
+	return stmts
 }
 
 // generateUpdateSQL 生成 UPDATE SQL
-func (sg *SQLGenerator) generateUpdateSQL(tableName, pkColumn string, updateRow models.UpdateRow) string {
-	var setParts []string
+// 差异只在键列或生成列（随其他列自动更新）时没有可写入的列，返回 false
+func (sg *SQLGenerator) generateUpdateSQL(tableName string, keyColumns []string, updateRow models.UpdateRow, skipColumns map[string]bool) (models.Statement, bool) {
+	isKeyColumn := make(map[string]bool)
+	for _, col := range keyColumns {
+		isKeyColumn[col] = true
+	}
 
-	for col, newVal := range updateRow.NewValues {
-		if col == pkColumn {
-			continue // 主键不更新
+	var columns []string
+	for col := range updateRow.NewValues {
+		if isKeyColumn[col] || skipColumns[col] {
+			continue // 主键和生成列不更新
 		}
-		setParts = append(setParts, fmt.Sprintf("`%s` = %s", col, sg.escapeValue(newVal)))
+		columns = append(columns, col)
+	}
+	if len(columns) == 0 {
+		return models.Statement{}, false
 	}
+	sort.Strings(columns) // 保证生成的 SQL 稳定
 
-	setClause := strings.Join(setParts, ", ")
-	whereClause := fmt.Sprintf("`%s` = %s", pkColumn, sg.escapeValue(updateRow.PrimaryKeyValue))
+	setParts := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, col := range columns {
+		setParts[i] = fmt.Sprintf("`%s` = ?", col)
+		args[i] = updateRow.NewValues[col]
+	}
 
-	return fmt.Sprintf("UPDATE `%s` SET %s WHERE %s;", tableName, setClause, whereClause)
+	setClause := strings.Join(setParts, ", ")
+	whereClause, whereArgs := buildKeyWhereClause(keyColumns, updateRow.Key, "=")
+
+	return models.Statement{
+		Kind:   models.StatementUpdate,
+		Table:  tableName,
+		Object: describeRow(keyColumns, updateRow.Key),
+		SQL:    fmt.Sprintf("UPDATE `%s` SET %s WHERE %s", tableName, setClause, whereClause),
+		Args:   append(args, whereArgs...),
+	}, true
 }
 
 // generateDeleteSQL 生成 DELETE SQL
-func (sg *SQLGenerator) generateDeleteSQL(tableName, pkColumn string, rows []map[string]interface{}) []string {
-	var sqls []string
+func (sg *SQLGenerator) generateDeleteSQL(tableName string, keyColumns []string, rows []map[string]interface{}) []models.Statement {
+	var stmts []models.Statement
+
+	for _, row := range rows {
+		key := models.RowKeyOf(row, keyColumns)
+		whereClause, args := buildKeyWhereClause(keyColumns, key, "=")
+		stmts = append(stmts, models.Statement{
+			Kind:   models.StatementDelete,
+			Table:  tableName,
+			Object: describeRow(keyColumns, key),
+			SQL:    fmt.Sprintf("DELETE FROM `%s` WHERE %s", tableName, whereClause),
+			Args:   args,
+		})
+	}
+
+	return stmts
+}
+
+// generateDeleteByRowSQL 为没有可用键的表生成 DELETE SQL
+// 按整行内容匹配（NULL 安全的 <=>），并用 LIMIT 1 保证重复行每次只删除一行
+func (sg *SQLGenerator) generateDeleteByRowSQL(tableName string, columns []string, rows []map[string]interface{}) []models.Statement {
+	var stmts []models.Statement
 
 	for _, row := range rows {
-		pkValue := row[pkColumn]
-		sql := fmt.Sprintf("DELETE FROM `%s` WHERE `%s` = %s;", tableName, pkColumn, sg.escapeValue(pkValue))
-		sqls = append(sqls, sql)
+		key := models.RowKeyOf(row, columns)
+		whereClause, args := buildKeyWhereClause(columns, key, "<=>")
+		stmts = append(stmts, models.Statement{
+			Kind:   models.StatementDelete,
+			Table:  tableName,
+			Object: "row with identical values",
+			SQL:    fmt.Sprintf("DELETE FROM `%s` WHERE %s LIMIT 1", tableName, whereClause),
+			Args:   args,
+		})
 	}
 
-	return sqls
+	return stmts
+}
+
+// buildKeyWhereClause 构建按键列定位一行的 WHERE 条件及参数，复合主键的各列以 AND 连接
+func buildKeyWhereClause(keyColumns []string, key models.RowKey, operator string) (string, []interface{}) {
+	parts := make([]string, len(keyColumns))
+	args := make([]interface{}, len(keyColumns))
+	for i, col := range keyColumns {
+		parts[i] = fmt.Sprintf("`%s` %s ?", col, operator)
+		args[i] = key[i]
+	}
+	return strings.Join(parts, " AND "), args
+}
+
+// describeRow 生成用于展示的行标识，如 "row (`user_id` = 1, `role_id` = 2)"
+func describeRow(keyColumns []string, key models.RowKey) string {
+	parts := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		parts[i] = fmt.Sprintf("`%s` = %s", col, FormatLiteral(key[i]))
+	}
+	return "row (" + strings.Join(parts, ", ") + ")"
 }
 
 // buildColumnDefinition 构建完整的列定义 SQL
@@ -237,10 +824,30 @@ func (sg *SQLGenerator) buildColumnDefinition(col models.Column) string {
 		}
 	}
 
+	// 字符集和排序规则是数据类型的一部分，须紧跟在类型之后
+	if col.Charset != nil {
+		sb.WriteString(" CHARACTER SET " + *col.Charset)
+	}
+	if col.Collation != nil {
+		sb.WriteString(" COLLATE " + *col.Collation)
+	}
+
+	// 生成列：不能有默认值和自增
+	if col.IsGenerated() {
+		sb.WriteString(fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", normalizeExpression(col.GenerationExpression), col.GeneratedStorage))
+		if !col.IsNullable {
+			sb.WriteString(" NOT NULL")
+		}
+		if col.Comment != nil {
+			sb.WriteString(" COMMENT " + FormatLiteral(*col.Comment))
+		}
+		return sb.String()
+	}
+
 	// 默认值
 	if col.DefaultValue != nil {
 		sb.WriteString(" DEFAULT ")
-		sb.WriteString(sg.escapeValue(*col.DefaultValue))
+		sb.WriteString(buildDefaultValue(col))
 	}
 
 	// 非空约束
@@ -248,27 +855,38 @@ func (sg *SQLGenerator) buildColumnDefinition(col models.Column) string {
 		sb.WriteString(" NOT NULL")
 	}
 
+	// ON UPDATE
+	if col.OnUpdate != "" {
+		sb.WriteString(" ON UPDATE " + col.OnUpdate)
+	}
+
 	// 自增
 	if col.IsAutoIncrement {
 		sb.WriteString(" AUTO_INCREMENT")
 	}
 
-	// 字符集和排序规则（如果指定）
-	if col.Charset != nil {
-		sb.WriteString(" CHARACTER SET " + *col.Charset)
-	}
-	if col.Collation != nil {
-		sb.WriteString(" COLLATE " + *col.Collation)
-	}
-
 	// 列注释
 	if col.Comment != nil {
-		sb.WriteString(" COMMENT " + sg.escapeValue(*col.Comment))
+		sb.WriteString(" COMMENT " + FormatLiteral(*col.Comment))
 	}
 
 	return sb.String()
 }
 
+// buildDefaultValue 构建 DEFAULT 子句的值
+// 字面量按字符串转义；CURRENT_TIMESTAMP 原样输出；其他表达式（MySQL 8.0.13+）需要用括号包围
+func buildDefaultValue(col models.Column) string {
+	value := *col.DefaultValue
+	switch {
+	case !col.DefaultIsExpression:
+		return FormatLiteral(value)
+	case models.IsCurrentTimestamp(value):
+		return value
+	default:
+		return "(" + value + ")"
+	}
+}
+
 // generateCreateTableSQL 生成 CREATE TABLE 语句，使用 SHOW CREATE TABLE 获取原始建表语句
 func (sg *SQLGenerator) generateCreateTableSQL(tableName string, tableDef *models.TableDefinition) (string, error) {
 	// 从源数据库获取原始的 CREATE TABLE 语句
@@ -276,30 +894,5 @@ func (sg *SQLGenerator) generateCreateTableSQL(tableName string, tableDef *model
 	if err != nil {
 		return "", fmt.Errorf("failed to get create table statement for %s: %w", tableName, err)
 	}
-	return createSQL + ";", nil
-}
-
-// escapeValue 转义 SQL 值
-func (sg *SQLGenerator) escapeValue(val interface{}) string {
-	if val == nil {
-		return "NULL"
-	}
-
-	switch v := val.(type) {
-	case string:
-		// 转义单引号和反斜杠
-		escaped := strings.ReplaceAll(v, "\\", "\\\\")
-		escaped = strings.ReplaceAll(escaped, "'", "\\'")
-		return fmt.Sprintf("'%s'", escaped)
-	case int, int64, float64:
-		return fmt.Sprintf("%v", v)
-	case bool:
-		if v {
-			return "1"
-		}
-		return "0"
-	default:
-		escaped := strings.ReplaceAll(fmt.Sprintf("%v", v), "'", "\\'")
-		return fmt.Sprintf("'%s'", escaped)
-	}
+	return createSQL, nil
 }
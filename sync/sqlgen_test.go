@@ -0,0 +1,556 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yuhuo/sync-db/config"
+	"github.com/yuhuo/sync-db/database"
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestBuildIndexDefinition(t *testing.T) {
+	tests := []struct {
+		index    models.Index
+		expected string
+	}{
+		{
+			models.Index{Name: "PRIMARY", Type: "PRIMARY", Visible: true,
+				Parts: []models.IndexPart{{Column: "user_id"}, {Column: "role_id"}}},
+			"PRIMARY KEY (`user_id`, `role_id`)",
+		},
+		{
+			models.Index{Name: "uk_email", Type: "UNIQUE", Method: "BTREE", Visible: true,
+				Parts: []models.IndexPart{{Column: "email", SubPart: 20}}},
+			"UNIQUE KEY `uk_email` (`email`(20))",
+		},
+		{
+			models.Index{Name: "ft_body", Type: "FULLTEXT", Visible: true, Comment: "search",
+				Parts: []models.IndexPart{{Column: "body"}}},
+			"FULLTEXT KEY `ft_body` (`body`) COMMENT 'search'",
+		},
+		{
+			models.Index{Name: "idx_created", Type: "INDEX", Method: "BTREE", Visible: false,
+				Parts: []models.IndexPart{{Column: "created_at", Descending: true}, {Expression: "lower(`name`)"}}},
+			"INDEX `idx_created` (`created_at` DESC, (lower(`name`))) INVISIBLE",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := buildIndexDefinition(tt.index); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+}
+
+func TestBuildTargetIndexDefinition(t *testing.T) {
+	idx := models.Index{Name: "idx_created", Type: "INDEX", Visible: false,
+		Parts: []models.IndexPart{{Column: "created_at", Descending: true}, {Column: "id"}}}
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"8.0.36", "INDEX `idx_created` (`created_at` DESC, `id`) INVISIBLE"},
+		{"5.7.44", "INDEX `idx_created` (`created_at`, `id`)"},
+		{"10.6.16-MariaDB", "INDEX `idx_created` (`created_at`, `id`) IGNORED"},
+		{"10.11.6-MariaDB", "INDEX `idx_created` (`created_at` DESC, `id`) IGNORED"},
+		{"10.5.23-MariaDB", "INDEX `idx_created` (`created_at`, `id`)"},
+	}
+
+	for _, tt := range tests {
+		sg := &SQLGenerator{targetVersion: database.ParseServerVersion(tt.version)}
+		if got := sg.buildTargetIndexDefinition(idx); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.version, tt.expected, got)
+		}
+	}
+	if !idx.Parts[0].Descending {
+		t.Error("Expected the original index parts to be left unchanged")
+	}
+}
+
+func TestOrderByForeignKeyDependency(t *testing.T) {
+	newTable := func(name string, refs ...string) models.StructureDifference {
+		def := &models.TableDefinition{TableName: name}
+		for _, ref := range refs {
+			def.ForeignKeys = append(def.ForeignKeys, models.ForeignKey{Name: "fk_" + ref, ReferencedTable: ref})
+		}
+		return models.StructureDifference{TableName: name, IsNewTable: true, TableDefinition: def}
+	}
+
+	diffs := []models.StructureDifference{
+		newTable("order_items", "orders", "products"),
+		newTable("orders", "users"),
+		{TableName: "users"},
+		newTable("products"),
+	}
+
+	var got []string
+	for _, sd := range orderByForeignKeyDependency(diffs) {
+		got = append(got, sd.TableName)
+	}
+
+	expected := []string{"users", "orders", "products", "order_items"}
+	for i := range expected {
+		if i >= len(got) || got[i] != expected[i] {
+			t.Fatalf("Expected order %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestBuildForeignKeyDefinition(t *testing.T) {
+	fk := models.ForeignKey{
+		Name:              "fk_user_role",
+		Columns:           []string{"user_id", "role_id"},
+		ReferencedTable:   "user_roles",
+		ReferencedColumns: []string{"user_id", "role_id"},
+		OnDelete:          "CASCADE",
+		OnUpdate:          "RESTRICT",
+	}
+
+	expected := "CONSTRAINT `fk_user_role` FOREIGN KEY (`user_id`, `role_id`) REFERENCES `user_roles` (`user_id`, `role_id`) ON DELETE CASCADE ON UPDATE RESTRICT"
+	if got := buildForeignKeyDefinition(fk); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestGenerateCreateTriggerSQLOrdering(t *testing.T) {
+	source := []models.TriggerDefinition{
+		{TriggerName: "trg_a", TableName: "orders", Timing: "BEFORE", Event: "INSERT", ActionOrder: 1, Body: "SET NEW.a = 1"},
+		{TriggerName: "trg_b", TableName: "orders", Timing: "BEFORE", Event: "INSERT", ActionOrder: 2, Body: "SET NEW.b = 1"},
+		{TriggerName: "trg_c", TableName: "orders", Timing: "BEFORE", Event: "INSERT", ActionOrder: 3, Body: "SET NEW.c = 1"},
+	}
+	diffs := []models.TriggerDifference{
+		{TriggerName: "trg_c", TableName: "orders", Operation: "CREATE", NewTrigger: &source[2]},
+		{TriggerName: "trg_a", TableName: "orders", Operation: "CREATE", NewTrigger: &source[0]},
+	}
+
+	stmts := generateCreateTriggerSQL(diffs, source)
+	expected := []string{
+		"CREATE TRIGGER `trg_a` BEFORE INSERT ON `orders` FOR EACH ROW PRECEDES `trg_b` SET NEW.a = 1",
+		"CREATE TRIGGER `trg_c` BEFORE INSERT ON `orders` FOR EACH ROW FOLLOWS `trg_b` SET NEW.c = 1",
+	}
+	if len(stmts) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(stmts))
+	}
+	for i, stmt := range stmts {
+		if stmt.SQL != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], stmt.SQL)
+		}
+	}
+}
+
+func TestGenerateEventSQL(t *testing.T) {
+	starts := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	event := models.EventDefinition{
+		EventName: "ev_cleanup", EventType: "RECURRING", IntervalValue: "1", IntervalField: "DAY",
+		Starts: &starts, Status: "ENABLED", OnCompletion: "NOT PRESERVE", Body: "DELETE FROM `logs`",
+	}
+	disabled := event
+	disabled.Status = "DISABLED"
+	diffs := []models.EventDifference{
+		{EventName: "ev_cleanup", Operation: "CREATE", NewEvent: &event},
+		{EventName: "ev_cleanup", Operation: "MODIFY", OldEvent: &disabled, NewEvent: &event},
+	}
+
+	sg := &SQLGenerator{options: config.GenerateConfig{CreateEventsDisabled: true}}
+	stmts := sg.generateEventSQL(diffs)
+	expected := "CREATE EVENT `ev_cleanup` ON SCHEDULE EVERY '1' DAY STARTS '2026-01-01 03:00:00' " +
+		"ON COMPLETION NOT PRESERVE DISABLE DO DELETE FROM `logs`"
+	if len(stmts) != 1 {
+		t.Fatalf("Expected only the CREATE statement, got %d statements", len(stmts))
+	}
+	if stmts[0].SQL != expected {
+		t.Errorf("Expected %s, got %s", expected, stmts[0].SQL)
+	}
+}
+
+func TestBuildGeneratedColumnDefinition(t *testing.T) {
+	sg := &SQLGenerator{}
+	col := models.Column{
+		Name: "total", Type: "decimal(10,2)", IsNullable: true,
+		GenerationExpression: "(`price` * `qty`)", GeneratedStorage: "STORED",
+	}
+
+	expected := "`total` decimal(10,2) GENERATED ALWAYS AS (`price` * `qty`) STORED"
+	if got := sg.buildColumnDefinition(col); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	plain := models.Column{Name: "total", Type: "decimal(10,2)", IsNullable: true}
+	if needsColumnRebuild(plain, col) {
+		t.Error("Expected STORED generated column to be convertible with MODIFY")
+	}
+	col.GeneratedStorage = "VIRTUAL"
+	if !needsColumnRebuild(plain, col) {
+		t.Error("Expected VIRTUAL generated column to require DROP and ADD")
+	}
+}
+
+func TestBuildColumnDefinitionDefaults(t *testing.T) {
+	current, uuid, literal, empty := "CURRENT_TIMESTAMP(3)", "uuid()", "CURRENT_TIMESTAMP", ""
+	charset, collation := "utf8mb4", "utf8mb4_bin"
+	tests := []struct {
+		col      models.Column
+		expected string
+	}{
+		{
+			models.Column{Name: "updated_at", Type: "datetime(3)", DefaultValue: &current, DefaultIsExpression: true, OnUpdate: "CURRENT_TIMESTAMP(3)"},
+			"`updated_at` datetime(3) DEFAULT CURRENT_TIMESTAMP(3) NOT NULL ON UPDATE CURRENT_TIMESTAMP(3)",
+		},
+		{
+			models.Column{Name: "token", Type: "char(36)", IsNullable: true, DefaultValue: &uuid, DefaultIsExpression: true},
+			"`token` char(36) DEFAULT (uuid())",
+		},
+		{
+			models.Column{Name: "note", Type: "varchar(20)", IsNullable: true, DefaultValue: &literal},
+			"`note` varchar(20) DEFAULT 'CURRENT_TIMESTAMP'",
+		},
+		{
+			// 字符集和排序规则紧跟在类型之后，写在 DEFAULT 和 NOT NULL 之后是语法错误
+			models.Column{Name: "code", Type: "varchar(32)", DefaultValue: &empty, Charset: &charset, Collation: &collation},
+			"`code` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT '' NOT NULL",
+		},
+	}
+
+	sg := &SQLGenerator{}
+	for _, tt := range tests {
+		if got := sg.buildColumnDefinition(tt.col); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+
+	now := "now(3)"
+	modified := tests[0].col
+	modified.DefaultValue = &now
+	if !columnsEqual(tests[0].col, modified) {
+		t.Error("Expected now(3) to equal CURRENT_TIMESTAMP(3)")
+	}
+	modified.OnUpdate = "CURRENT_TIMESTAMP"
+	if columnsEqual(tests[0].col, modified) {
+		t.Error("Expected different ON UPDATE precision to be detected")
+	}
+}
+
+func TestGenerateInsertSkipsGeneratedColumns(t *testing.T) {
+	sg := &SQLGenerator{}
+	rows := []map[string]interface{}{{"id": int64(1), "total": "9.00"}}
+
+	stmts := sg.generateInsertSQL("orders", []string{"id"}, rows, map[string]bool{"total": true})
+	expected := "INSERT INTO `orders` (`id`) VALUES (?)"
+	if len(stmts) != 1 || stmts[0].SQL != expected {
+		t.Errorf("Expected %s, got %v", expected, stmts)
+	}
+}
+
+func TestGenerateInsertSQLStableColumnOrder(t *testing.T) {
+	sg := &SQLGenerator{}
+	row := map[string]interface{}{"id": int64(1), "status": "paid", "amount": "9.00", "created_at": "2026-01-01", "note": nil}
+
+	expected := "INSERT INTO `orders` (`amount`, `created_at`, `id`, `note`, `status`) VALUES (?, ?, ?, ?, ?)"
+	for i := 0; i < 20; i++ {
+		stmts := sg.generateInsertSQL("orders", []string{"id"}, []map[string]interface{}{row}, nil)
+		if len(stmts) != 1 || stmts[0].SQL != expected {
+			t.Fatalf("Expected %s, got %v", expected, stmts)
+		}
+		if args := stmts[0].Args; args[0] != "9.00" || args[2] != int64(1) || args[4] != "paid" {
+			t.Fatalf("Expected args in column order, got %v", args)
+		}
+	}
+}
+
+func TestGenerateUpdateSQL(t *testing.T) {
+	sg := &SQLGenerator{}
+	skip := map[string]bool{"total": true}
+
+	row := models.UpdateRow{
+		Key:       models.RowKey{int64(1)},
+		NewValues: map[string]interface{}{"status": "paid", "amount": "9.00", "total": "9.00"},
+	}
+	stmt, ok := sg.generateUpdateSQL("orders", []string{"id"}, row, skip)
+	expected := "UPDATE `orders` SET `amount` = ?, `status` = ? WHERE `id` = ?"
+	if !ok || stmt.SQL != expected {
+		t.Errorf("Expected %s, got %s", expected, stmt.SQL)
+	}
+
+	// 只有生成列不同时没有可写入的列，不能生成 SET  WHERE
+	row.NewValues = map[string]interface{}{"total": "10.00"}
+	if stmt, ok := sg.generateUpdateSQL("orders", []string{"id"}, row, skip); ok {
+		t.Errorf("Expected no UPDATE when only generated columns differ, got %s", stmt.SQL)
+	}
+}
+
+func TestGenerateStructureSQLColumnPositions(t *testing.T) {
+	sg := &SQLGenerator{options: config.GenerateConfig{ReorderColumns: true}}
+	structDiff := models.StructureDifference{
+		TableName:        "users",
+		ColumnOrder:      []string{"id", "name", "nickname", "email", "created_at"},
+		ColumnsAdded:     []models.Column{{Name: "nickname", Type: "varchar(50)", IsNullable: true}, {Name: "created_at", Type: "datetime", IsNullable: true}},
+		ColumnsReordered: []models.Column{{Name: "name", Type: "varchar(100)", IsNullable: true}},
+	}
+
+	stmts, err := sg.generateStructureSQL(structDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"ALTER TABLE `users` MODIFY COLUMN `name` varchar(100) AFTER `id`",
+		"ALTER TABLE `users` ADD COLUMN `nickname` varchar(50) AFTER `name`",
+		"ALTER TABLE `users` ADD COLUMN `created_at` datetime",
+	}
+	if len(stmts) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(stmts))
+	}
+	for i, stmt := range stmts {
+		if stmt.SQL != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], stmt.SQL)
+		}
+	}
+}
+
+func TestGenerateColumnRenameSQL(t *testing.T) {
+	column := models.Column{Name: "mail", Type: "varchar(100)", IsNullable: true}
+	old := column
+	old.Name = "email"
+	structDiff := models.StructureDifference{
+		TableName:      "users",
+		ColumnsRenamed: []models.ColumnRename{{OldName: "email", NewName: "mail", OldColumn: old, NewColumn: column, Confirmed: true}},
+	}
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"8.0.36", "ALTER TABLE `users` RENAME COLUMN `email` TO `mail`"},
+		{"10.5.2-MariaDB", "ALTER TABLE `users` RENAME COLUMN `email` TO `mail`"},
+		// 不支持 RENAME COLUMN 的版本使用 CHANGE COLUMN 并带上完整定义
+		{"5.7.44", "ALTER TABLE `users` CHANGE COLUMN `email` `mail` varchar(100)"},
+		{"10.4.32-MariaDB", "ALTER TABLE `users` CHANGE COLUMN `email` `mail` varchar(100)"},
+	}
+
+	for _, tt := range tests {
+		sg := &SQLGenerator{targetVersion: database.ParseServerVersion(tt.version)}
+		stmts, err := sg.generateStructureSQL(structDiff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stmts) != 1 || stmts[0].SQL != tt.expected {
+			t.Errorf("%s: expected %s, got %v", tt.version, tt.expected, stmts)
+		}
+	}
+}
+
+func TestGenerateIndexRenameSQL(t *testing.T) {
+	idx := models.Index{Name: "idx_user_email", Type: "INDEX", Visible: true, Parts: []models.IndexPart{{Column: "email"}}}
+	structDiff := models.StructureDifference{
+		TableName:      "users",
+		IndexesRenamed: []models.IndexRename{{OldName: "idx_email", NewName: "idx_user_email", Index: idx}},
+	}
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"5.7.44", "ALTER TABLE `users` RENAME INDEX `idx_email` TO `idx_user_email`"},
+		{"10.5.2-MariaDB", "ALTER TABLE `users` RENAME INDEX `idx_email` TO `idx_user_email`"},
+		{"10.4.32-MariaDB", "ALTER TABLE `users` DROP INDEX `idx_email`, ADD INDEX `idx_user_email` (`email`)"},
+	}
+
+	for _, tt := range tests {
+		sg := &SQLGenerator{targetVersion: database.ParseServerVersion(tt.version)}
+		stmts, err := sg.generateStructureSQL(structDiff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stmts) != 1 || stmts[0].SQL != tt.expected {
+			t.Errorf("%s: expected %s, got %v", tt.version, tt.expected, stmts)
+		}
+	}
+}
+
+func TestGenerateSQLConfirmedTableRename(t *testing.T) {
+	diff := &models.SyncDifference{
+		StructureDifferences: []models.StructureDifference{{
+			TableName:       "user_profiles",
+			IsNewTable:      true,
+			TableDefinition: &models.TableDefinition{TableName: "user_profiles"},
+		}},
+		TableRenames: []models.TableRename{{OldName: "user_profile", NewName: "user_profiles"}},
+	}
+	diff.ConfirmTableRename(0)
+
+	stmts, err := (&SQLGenerator{}).GenerateSQL(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "RENAME TABLE `user_profile` TO `user_profiles`"
+	if len(stmts) != 1 || stmts[0].SQL != expected {
+		t.Errorf("Expected only %s, got %v", expected, stmts)
+	}
+}
+
+func TestGenerateOrphanTableSQL(t *testing.T) {
+	orphans := []models.OrphanTable{
+		{TableName: "old_logs", Action: models.OrphanArchive},
+		{TableName: "tmp_a", Action: models.OrphanDrop},
+		{TableName: "tmp_b", Action: models.OrphanDrop},
+		{TableName: "legacy", Action: models.OrphanReport},
+	}
+
+	stmts := generateOrphanTableSQL(orphans, time.Date(2026, 2, 7, 22, 57, 15, 0, time.UTC))
+	expected := []string{
+		"RENAME TABLE `old_logs` TO `old_logs_archived_20260207225715`",
+		"DROP TABLE IF EXISTS `tmp_a`, `tmp_b`",
+	}
+	if len(stmts) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(stmts))
+	}
+	for i, stmt := range stmts {
+		if stmt.SQL != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], stmt.SQL)
+		}
+	}
+}
+
+func TestGenerateTableOptionSQL(t *testing.T) {
+	changes := []models.TableOptionChange{
+		{Option: "ENGINE", OldValue: "MyISAM", NewValue: "InnoDB", Rebuild: true},
+		{Option: "COMMENT", OldValue: "", NewValue: "user's table"},
+		{Option: "CONVERT TO CHARACTER SET", OldValue: "utf8mb3_general_ci", NewValue: "utf8mb4_0900_ai_ci", Rebuild: true},
+		{Option: "COMPRESSION", OldValue: "zlib", NewValue: ""},
+	}
+
+	stmts := generateTableOptionSQL("users", changes)
+	expected := []string{
+		"ALTER TABLE `users` CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+		"ALTER TABLE `users` ENGINE = InnoDB COMMENT = 'user\\'s table' COMPRESSION = 'None'",
+	}
+	if len(stmts) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(stmts))
+	}
+	for i, stmt := range stmts {
+		if stmt.SQL != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], stmt.SQL)
+		}
+	}
+}
+
+func TestGeneratePartitionSQL(t *testing.T) {
+	target := &models.Partitioning{Method: "RANGE", Expression: "to_days(`created_at`)", Partitions: []models.Partition{
+		{Name: "p202607", Description: "739829"},
+		{Name: "p202608", Description: "739860"},
+		{Name: "pmax", Description: "MAXVALUE"},
+	}}
+	source := &models.Partitioning{Method: "RANGE", Expression: "to_days(`created_at`)", Partitions: []models.Partition{
+		{Name: "p202608", Description: "739860"},
+		{Name: "p202609", Description: "739890"},
+		{Name: "pmax", Description: "MAXVALUE"},
+	}}
+
+	change := comparePartitioning(source, target)
+	if change == nil || change.Repartition {
+		t.Fatalf("Expected partition-level changes, got %+v", change)
+	}
+
+	expected := []string{
+		"ALTER TABLE `event_logs` REORGANIZE PARTITION `pmax` INTO (PARTITION `p202609` VALUES LESS THAN (739890), PARTITION `pmax` VALUES LESS THAN MAXVALUE)",
+	}
+	sg := &SQLGenerator{}
+	stmts := sg.generatePartitionSQL("event_logs", change)
+	if len(stmts) != len(expected) || stmts[0].SQL != expected[0] {
+		t.Errorf("Expected %v without DROP PARTITION, got %v", expected, stmts)
+	}
+
+	sg.options = config.GenerateConfig{DropPartitions: true}
+	stmts = sg.generatePartitionSQL("event_logs", change)
+	expected = append([]string{"ALTER TABLE `event_logs` DROP PARTITION `p202607`"}, expected...)
+	if len(stmts) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(stmts))
+	}
+	for i, stmt := range stmts {
+		if stmt.SQL != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], stmt.SQL)
+		}
+	}
+}
+
+func TestGenerateListPartitionSQL(t *testing.T) {
+	target := &models.Partitioning{Method: "LIST", Expression: "`region_id`", Partitions: []models.Partition{
+		{Name: "p_north", Description: "1,2"},
+		{Name: "p_south", Description: "3,4"},
+		{Name: "p_legacy", Description: "9"},
+	}}
+	source := &models.Partitioning{Method: "LIST", Expression: "`region_id`", Partitions: []models.Partition{
+		{Name: "p_north", Description: "1,2"},
+		{Name: "p_south", Description: "3,4,5"},
+		{Name: "p_west", Description: "6,7"},
+	}}
+
+	change := comparePartitioning(source, target)
+	if change == nil || change.Repartition {
+		t.Fatalf("Expected partition-level changes, got %+v", change)
+	}
+
+	// 值列表变化的分区原地重组，新分区追加，目标库独有的分区只在开启 drop_partitions 时删除
+	expected := []string{
+		"ALTER TABLE `orders` REORGANIZE PARTITION `p_south` INTO (PARTITION `p_south` VALUES IN (3,4,5))",
+		"ALTER TABLE `orders` ADD PARTITION (PARTITION `p_west` VALUES IN (6,7))",
+	}
+	sg := &SQLGenerator{}
+	for _, dropPartitions := range []bool{false, true} {
+		sg.options = config.GenerateConfig{DropPartitions: dropPartitions}
+		want := expected
+		if dropPartitions {
+			want = append([]string{"ALTER TABLE `orders` DROP PARTITION `p_legacy`"}, expected...)
+		}
+		stmts := sg.generatePartitionSQL("orders", change)
+		if len(stmts) != len(want) {
+			t.Fatalf("drop_partitions=%v: expected %d statements, got %v", dropPartitions, len(want), stmts)
+		}
+		for i, stmt := range stmts {
+			if stmt.SQL != want[i] {
+				t.Errorf("drop_partitions=%v: expected %s, got %s", dropPartitions, want[i], stmt.SQL)
+			}
+		}
+	}
+}
+
+func TestGenerateDeleteSQLCompositeKey(t *testing.T) {
+	sg := &SQLGenerator{}
+	keyColumns := []string{"user_id", "role_id"}
+	rows := []map[string]interface{}{{"user_id": int64(1), "role_id": int64(2), "granted_at": "2026-01-01"}}
+
+	stmts := sg.generateDeleteSQL("user_roles", keyColumns, rows)
+	if len(stmts) != 1 {
+		t.Fatalf("Expected 1 statement, got %d", len(stmts))
+	}
+	expected := "DELETE FROM `user_roles` WHERE `user_id` = ? AND `role_id` = ?"
+	if stmts[0].SQL != expected {
+		t.Errorf("Expected %s, got %s", expected, stmts[0].SQL)
+	}
+	if len(stmts[0].Args) != 2 || stmts[0].Args[0] != int64(1) || stmts[0].Args[1] != int64(2) {
+		t.Errorf("Expected args [1 2] in key order, got %v", stmts[0].Args)
+	}
+	if expected := "row (`user_id` = 1, `role_id` = 2)"; stmts[0].Object != expected {
+		t.Errorf("Expected %s, got %s", expected, stmts[0].Object)
+	}
+}
+
+func TestGenerateDataSQLDeletesBeforeInserts(t *testing.T) {
+	sg := &SQLGenerator{}
+	diff := models.DataDifference{
+		TableName:    "tags",
+		KeyColumns:   []string{"name"},
+		MatchMode:    models.MatchByPrimaryKey,
+		RowsToInsert: []map[string]interface{}{{"name": "Go"}},
+		RowsToDelete: []map[string]interface{}{{"name": "go"}},
+	}
+
+	stmts, err := sg.generateDataSQL(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 || stmts[0].Kind != models.StatementDelete || stmts[1].Kind != models.StatementInsert {
+		t.Errorf("Expected DELETE before INSERT for keys differing only in case, got %v", stmts)
+	}
+}
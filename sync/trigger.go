@@ -0,0 +1,170 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// compareTriggers 比对触发器差异
+// 比对所属表、触发时机、事件、执行顺序和触发器语句（忽略空白差异）
+func (c *Comparator) compareTriggers() ([]models.TriggerDifference, error) {
+	sourceTriggers, err := c.sourceQueryHelper.GetTriggers()
+	if err != nil {
+		return nil, err
+	}
+
+	targetTriggers, err := c.targetQueryHelper.GetTriggers()
+	if err != nil {
+		return nil, err
+	}
+
+	return diffTriggers(sourceTriggers, targetTriggers), nil
+}
+
+// diffTriggers 比对源库和目标库的触发器，定义变化的触发器先删除再创建
+func diffTriggers(sourceTriggers, targetTriggers []models.TriggerDefinition) []models.TriggerDifference {
+	sourceTriggerMap := make(map[string]models.TriggerDefinition)
+	for _, trigger := range sourceTriggers {
+		sourceTriggerMap[trigger.TriggerName] = trigger
+	}
+
+	targetTriggerMap := make(map[string]models.TriggerDefinition)
+	for _, trigger := range targetTriggers {
+		targetTriggerMap[trigger.TriggerName] = trigger
+	}
+
+	var triggerDiffs []models.TriggerDifference
+
+	// 检查新增和修改的触发器
+	for i := range sourceTriggers {
+		sourceTrigger := &sourceTriggers[i]
+		targetTrigger, exists := targetTriggerMap[sourceTrigger.TriggerName]
+		if !exists {
+			triggerDiffs = append(triggerDiffs, models.TriggerDifference{
+				TriggerName: sourceTrigger.TriggerName,
+				TableName:   sourceTrigger.TableName,
+				Operation:   "CREATE",
+				NewTrigger:  sourceTrigger,
+			})
+		} else if !triggersEqual(*sourceTrigger, targetTrigger) {
+			triggerDiffs = append(triggerDiffs, models.TriggerDifference{
+				TriggerName: sourceTrigger.TriggerName,
+				TableName:   sourceTrigger.TableName,
+				Operation:   "MODIFY",
+				OldTrigger:  &targetTrigger,
+				NewTrigger:  sourceTrigger,
+			})
+		}
+	}
+
+	// 检查删除的触发器
+	for i := range targetTriggers {
+		targetTrigger := &targetTriggers[i]
+		if _, exists := sourceTriggerMap[targetTrigger.TriggerName]; !exists {
+			triggerDiffs = append(triggerDiffs, models.TriggerDifference{
+				TriggerName: targetTrigger.TriggerName,
+				TableName:   targetTrigger.TableName,
+				Operation:   "DROP",
+				OldTrigger:  targetTrigger,
+			})
+		}
+	}
+
+	return triggerDiffs
+}
+
+// triggersEqual 判断两个触发器定义是否相同
+func triggersEqual(t1, t2 models.TriggerDefinition) bool {
+	return t1.TableName == t2.TableName &&
+		t1.Timing == t2.Timing &&
+		t1.Event == t2.Event &&
+		t1.ActionOrder == t2.ActionOrder &&
+		normalizeWhitespace(t1.Body) == normalizeWhitespace(t2.Body)
+}
+
+// normalizeWhitespace 去除首尾空白并将连续的空白字符合并为一个空格
+func normalizeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// generateDropTriggerSQL 生成删除触发器的语句（DROP 和 MODIFY 的触发器）
+func generateDropTriggerSQL(triggerDiffs []models.TriggerDifference) []models.Statement {
+	var stmts []models.Statement
+	for _, triggerDiff := range triggerDiffs {
+		if triggerDiff.Operation == "DROP" || triggerDiff.Operation == "MODIFY" {
+			stmts = append(stmts, models.Statement{
+				Kind:   models.StatementDropTrigger,
+				Table:  triggerDiff.TableName,
+				Object: fmt.Sprintf("trigger `%s`", triggerDiff.TriggerName),
+				SQL:    fmt.Sprintf("DROP TRIGGER IF EXISTS `%s`", triggerDiff.TriggerName),
+			})
+		}
+	}
+	return stmts
+}
+
+// generateCreateTriggerSQL 生成创建触发器的语句（CREATE 和 MODIFY 的触发器）
+// 同一表、时机和事件的触发器按 ACTION_ORDER 依次创建，并用 FOLLOWS/PRECEDES 还原执行顺序
+func generateCreateTriggerSQL(triggerDiffs []models.TriggerDifference, sourceTriggers []models.TriggerDefinition) []models.Statement {
+	var creating []models.TriggerDefinition
+	creatingNames := make(map[string]bool)
+	for _, triggerDiff := range triggerDiffs {
+		if triggerDiff.Operation == "CREATE" || triggerDiff.Operation == "MODIFY" {
+			creating = append(creating, *triggerDiff.NewTrigger)
+			creatingNames[triggerDiff.TriggerName] = true
+		}
+	}
+
+	sort.SliceStable(creating, func(i, j int) bool {
+		if triggerGroup(creating[i]) != triggerGroup(creating[j]) {
+			return triggerGroup(creating[i]) < triggerGroup(creating[j])
+		}
+		return creating[i].ActionOrder < creating[j].ActionOrder
+	})
+
+	// 源库中每组触发器按执行顺序排列，用于确定前后相邻的触发器
+	groups := make(map[string][]models.TriggerDefinition)
+	for _, trigger := range sourceTriggers {
+		groups[triggerGroup(trigger)] = append(groups[triggerGroup(trigger)], trigger)
+	}
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].ActionOrder < group[j].ActionOrder })
+	}
+
+	var stmts []models.Statement
+	for _, trigger := range creating {
+		ordering := ""
+		group := groups[triggerGroup(trigger)]
+		for i, t := range group {
+			if t.TriggerName != trigger.TriggerName {
+				continue
+			}
+			if i > 0 {
+				// 前一个触发器要么未变化，要么已按顺序先创建
+				ordering = fmt.Sprintf(" FOLLOWS `%s`", group[i-1].TriggerName)
+			} else if i+1 < len(group) && !creatingNames[group[i+1].TriggerName] {
+				// 排在第一位且后一个触发器已存在于目标库时，需要显式放在它前面
+				ordering = fmt.Sprintf(" PRECEDES `%s`", group[i+1].TriggerName)
+			}
+			break
+		}
+
+		stmts = append(stmts, models.Statement{
+			Kind:   models.StatementCreateTrigger,
+			Table:  trigger.TableName,
+			Object: fmt.Sprintf("trigger `%s`", trigger.TriggerName),
+			SQL: fmt.Sprintf("CREATE TRIGGER `%s` %s %s ON `%s` FOR EACH ROW%s %s",
+				trigger.TriggerName, trigger.Timing, trigger.Event, trigger.TableName, ordering, trigger.Body),
+		})
+	}
+
+	return stmts
+}
+
+// triggerGroup 返回触发器的分组键：同一表、时机和事件的触发器共享执行顺序
+func triggerGroup(trigger models.TriggerDefinition) string {
+	return trigger.TableName + "\x00" + trigger.Timing + "\x00" + trigger.Event
+}
@@ -0,0 +1,43 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestDiffTriggers(t *testing.T) {
+	trigger := func(name, body string) models.TriggerDefinition {
+		return models.TriggerDefinition{TriggerName: name, TableName: "orders", Timing: "AFTER", Event: "INSERT", ActionOrder: 1, Body: body}
+	}
+	source := []models.TriggerDefinition{
+		trigger("trg_audit", "INSERT INTO audit_log VALUES (NEW.id)"),
+		trigger("trg_stock", "UPDATE stock SET qty = qty - 1"),
+		trigger("trg_notify", "INSERT INTO outbox VALUES (NEW.id, 'created')"),
+	}
+	target := []models.TriggerDefinition{
+		trigger("trg_audit", "INSERT INTO audit_log\n  VALUES (NEW.id)"),
+		trigger("trg_stock", "UPDATE stock SET qty = qty - 2"),
+		trigger("trg_legacy", "DELETE FROM cache"),
+	}
+
+	diffs := diffTriggers(source, target)
+
+	// 只有空白差异的触发器视为相同
+	expected := []struct{ name, operation string }{
+		{"trg_stock", "MODIFY"},
+		{"trg_notify", "CREATE"},
+		{"trg_legacy", "DROP"},
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("Expected %d trigger diffs, got %+v", len(expected), diffs)
+	}
+	for i, e := range expected {
+		if diffs[i].TriggerName != e.name || diffs[i].Operation != e.operation {
+			t.Errorf("Expected %s %s, got %s %s", e.operation, e.name, diffs[i].Operation, diffs[i].TriggerName)
+		}
+	}
+	if diffs[0].OldTrigger.Body != target[1].Body || diffs[0].NewTrigger.Body != source[1].Body {
+		t.Errorf("Expected MODIFY to carry both definitions, got %+v", diffs[0])
+	}
+}
@@ -0,0 +1,293 @@
+package sync
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// columnType 表示解析后的列类型，如 "decimal(10,2) unsigned"
+type columnType struct {
+	Base     string   // 大写的基础类型，如 DECIMAL
+	Params   []string // 括号内的参数，保持原样（ENUM/SET 的取值区分大小写）
+	Unsigned bool
+	Zerofill bool
+}
+
+// integerRanks 整数类型按取值范围从小到大排序
+var integerRanks = map[string]int{"TINYINT": 1, "SMALLINT": 2, "MEDIUMINT": 3, "INT": 4, "BIGINT": 5}
+
+// textCapacities 字符串/二进制大对象类型可容纳的最大字节数
+var textCapacities = map[string]int64{
+	"TINYTEXT": 255, "TEXT": 65535, "MEDIUMTEXT": 16777215, "LONGTEXT": 4294967295,
+	"TINYBLOB": 255, "BLOB": 65535, "MEDIUMBLOB": 16777215, "LONGBLOB": 4294967295,
+}
+
+// temporalRanks 日期时间类型按取值范围从小到大排序：TIMESTAMP（1970-2038）小于 DATE 和 DATETIME（1000-9999）
+var temporalRanks = map[string]int{"TIMESTAMP": 1, "DATE": 2, "DATETIME": 3}
+
+// charsetMaxBytes 常用字符集每个字符最多占用的字节数，未列出的字符集按 4 字节计算
+var charsetMaxBytes = map[string]int64{
+	"ascii": 1, "latin1": 1, "latin2": 1, "binary": 1,
+	"ucs2": 2, "gbk": 2, "gb2312": 2, "big5": 2,
+	"utf8": 3, "utf8mb3": 3, "gb18030": 4, "utf8mb4": 4, "utf16": 4, "utf32": 4,
+}
+
+// parseColumnType 解析 COLUMN_TYPE
+func parseColumnType(typeStr string) columnType {
+	typeStr = strings.TrimSpace(typeStr)
+
+	var ct columnType
+	rest := typeStr
+	if open := strings.Index(typeStr, "("); open > 0 {
+		if close := strings.LastIndex(typeStr, ")"); close > open {
+			ct.Base = typeStr[:open]
+			ct.Params = splitTypeParams(typeStr[open+1 : close])
+			rest = typeStr[close+1:]
+		}
+	}
+
+	fields := strings.Fields(strings.ToUpper(rest))
+	if ct.Base == "" && len(fields) > 0 {
+		ct.Base, fields = fields[0], fields[1:]
+	}
+	ct.Base = strings.ToUpper(strings.TrimSpace(ct.Base))
+	if ct.Base == "INTEGER" {
+		ct.Base = "INT"
+	}
+
+	for _, field := range fields {
+		switch field {
+		case "UNSIGNED":
+			ct.Unsigned = true
+		case "ZEROFILL":
+			ct.Zerofill = true
+		}
+	}
+
+	// 整数类型的显示宽度（如 int(11)）不影响取值范围，MySQL 8.0.19 起也不再显示，比对时忽略
+	if _, isInteger := integerRanks[ct.Base]; isInteger {
+		ct.Params = nil
+	}
+
+	return ct
+}
+
+// splitTypeParams 按逗号拆分类型参数，忽略引号内的逗号（ENUM/SET 取值中可能包含逗号）
+func splitTypeParams(params string) []string {
+	var result []string
+	var current strings.Builder
+	inQuote := false
+
+	for i := 0; i < len(params); i++ {
+		c := params[i]
+		switch {
+		case c == '\'' && inQuote && i+1 < len(params) && params[i+1] == '\'':
+			current.WriteString("''")
+			i++
+			continue
+		case c == '\'':
+			inQuote = !inQuote
+		case c == ',' && !inQuote:
+			result = append(result, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	if last := strings.TrimSpace(current.String()); last != "" || len(result) > 0 {
+		result = append(result, last)
+	}
+	return result
+}
+
+// String 返回规范化的类型字符串
+func (ct columnType) String() string {
+	var sb strings.Builder
+	sb.WriteString(ct.Base)
+	if len(ct.Params) > 0 {
+		sb.WriteString("(" + strings.Join(ct.Params, ",") + ")")
+	}
+	if ct.Unsigned {
+		sb.WriteString(" UNSIGNED")
+	}
+	if ct.Zerofill {
+		sb.WriteString(" ZEROFILL")
+	}
+	return sb.String()
+}
+
+// intParam 返回第 i 个参数的整数值，不存在时返回 defaultValue
+func (ct columnType) intParam(i int, defaultValue int64) int64 {
+	if i >= len(ct.Params) {
+		return defaultValue
+	}
+	n, err := strconv.ParseInt(ct.Params[i], 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// normalizeType 规范化列类型用于比对
+// 长度、精度、ENUM/SET 取值等参数都参与比对，只忽略大小写、多余空格和整数类型的显示宽度
+func normalizeType(typeStr string) string {
+	return parseColumnType(typeStr).String()
+}
+
+// classifyColumnChange 判断列修改是否可能丢失数据
+// WIDENING：现有数据都能无损保留（包括仅修改注释、默认值等不影响数据的情况）
+// NARROWING：同一类型族内缩小范围、长度或精度，部分现有数据可能被截断或导致执行失败
+// INCOMPATIBLE：跨类型族的转换，结果取决于具体数据
+func classifyColumnChange(oldCol, newCol models.Column) string {
+	maxBytes := max(maxBytesPerChar(oldCol.Charset), maxBytesPerChar(newCol.Charset))
+	result := classifyTypeChange(parseColumnType(oldCol.Type), parseColumnType(newCol.Type), maxBytes)
+
+	// 允许 NULL 改为 NOT NULL 时，已有的 NULL 值会被转换或导致失败
+	if oldCol.IsNullable && !newCol.IsNullable && result == models.ChangeWidening {
+		result = models.ChangeNarrowing
+	}
+
+	// 普通列与生成列之间转换时，已有的值会被表达式的结果覆盖或丢失
+	if oldCol.IsGenerated() != newCol.IsGenerated() && result == models.ChangeWidening {
+		result = models.ChangeNarrowing
+	}
+
+	return result
+}
+
+// classifyTypeChange 判断类型变化的兼容性，maxBytes 为字符集每个字符最多占用的字节数
+func classifyTypeChange(oldType, newType columnType, maxBytes int64) string {
+	if oldType.String() == newType.String() {
+		return models.ChangeWidening
+	}
+
+	// 整数类型：比较取值范围，有符号改为无符号会丢失负数
+	if oldRank, ok := integerRanks[oldType.Base]; ok {
+		if newRank, ok := integerRanks[newType.Base]; ok {
+			switch {
+			case !oldType.Unsigned && newType.Unsigned:
+				return models.ChangeNarrowing
+			case oldType.Unsigned && !newType.Unsigned:
+				return widenIf(newRank > oldRank)
+			default:
+				return widenIf(newRank >= oldRank)
+			}
+		}
+		if isDecimal(newType.Base) {
+			return widenIf(newType.intParam(0, 10)-newType.intParam(1, 0) >= integerDigits(oldType))
+		}
+		return models.ChangeIncompatible
+	}
+
+	// 定点数：整数位和小数位都不能减少
+	if isDecimal(oldType.Base) && isDecimal(newType.Base) {
+		oldScale, newScale := oldType.intParam(1, 0), newType.intParam(1, 0)
+		oldIntDigits := oldType.intParam(0, 10) - oldScale
+		newIntDigits := newType.intParam(0, 10) - newScale
+		return widenIf(newScale >= oldScale && newIntDigits >= oldIntDigits && (!newType.Unsigned || oldType.Unsigned))
+	}
+
+	// 浮点数：FLOAT → DOUBLE 无损
+	if isFloat(oldType.Base) && isFloat(newType.Base) {
+		return widenIf(newType.Base != "FLOAT" || oldType.Base == "FLOAT")
+	}
+
+	// 字符串和二进制：比较可容纳的长度，字符与二进制之间视为不兼容
+	// CHAR/VARCHAR 的长度以字符计，TEXT 以字节计，两者之间按最坏情况比较：
+	// 写入 TEXT 时每个字符按 maxBytes 字节计算，写入 VARCHAR 时 TEXT 的每个字节按一个字符计算
+	if oldCap, oldBinary, ok := stringCapacity(oldType); ok {
+		if newCap, newBinary, ok := stringCapacity(newType); ok && oldBinary == newBinary {
+			if !oldBinary && isCharType(oldType.Base) && !isCharType(newType.Base) {
+				oldCap *= maxBytes
+			}
+			return widenIf(newCap >= oldCap)
+		}
+		return models.ChangeIncompatible
+	}
+
+	// ENUM/SET：新的取值列表包含所有旧取值时无损
+	if (oldType.Base == "ENUM" || oldType.Base == "SET") && oldType.Base == newType.Base {
+		newValues := make(map[string]bool)
+		for _, v := range newType.Params {
+			newValues[v] = true
+		}
+		for _, v := range oldType.Params {
+			if !newValues[v] {
+				return models.ChangeNarrowing
+			}
+		}
+		return models.ChangeWidening
+	}
+
+	// 日期时间：取值范围不能缩小，转为 DATE 会丢失时间部分，小数秒精度不能减少
+	if oldRank, ok := temporalRanks[oldType.Base]; ok {
+		if newRank, ok := temporalRanks[newType.Base]; ok {
+			keepsTime := newType.Base != "DATE" || oldType.Base == "DATE"
+			return widenIf(newRank >= oldRank && keepsTime && newType.intParam(0, 0) >= oldType.intParam(0, 0))
+		}
+		return models.ChangeIncompatible
+	}
+	if oldType.Base == "TIME" && newType.Base == "TIME" {
+		return widenIf(newType.intParam(0, 0) >= oldType.intParam(0, 0))
+	}
+
+	return models.ChangeIncompatible
+}
+
+// widenIf 条件成立时返回 WIDENING，否则返回 NARROWING
+func widenIf(cond bool) string {
+	if cond {
+		return models.ChangeWidening
+	}
+	return models.ChangeNarrowing
+}
+
+func isDecimal(base string) bool {
+	return base == "DECIMAL" || base == "NUMERIC"
+}
+
+func isFloat(base string) bool {
+	return base == "FLOAT" || base == "DOUBLE" || base == "REAL"
+}
+
+// integerDigits 返回整数类型最大值的十进制位数
+func integerDigits(ct columnType) int64 {
+	digits := map[string]int64{"TINYINT": 3, "SMALLINT": 5, "MEDIUMINT": 8, "INT": 10, "BIGINT": 19}[ct.Base]
+	if ct.Base == "BIGINT" && ct.Unsigned {
+		digits = 20
+	}
+	return digits
+}
+
+// isCharType 判断是否为以字符计长度的字符串类型
+func isCharType(base string) bool {
+	return base == "CHAR" || base == "VARCHAR"
+}
+
+// maxBytesPerChar 返回字符集每个字符最多占用的字节数
+func maxBytesPerChar(charset *string) int64 {
+	if charset == nil {
+		return 4
+	}
+	if n, ok := charsetMaxBytes[strings.ToLower(*charset)]; ok {
+		return n
+	}
+	return 4
+}
+
+// stringCapacity 返回字符串/二进制类型可容纳的长度（CHAR/VARCHAR 为字符数，其余为字节数），以及是否为二进制类型
+func stringCapacity(ct columnType) (int64, bool, bool) {
+	switch ct.Base {
+	case "CHAR", "VARCHAR":
+		return ct.intParam(0, 1), false, true
+	case "BINARY", "VARBINARY":
+		return ct.intParam(0, 1), true, true
+	case "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
+		return textCapacities[ct.Base], false, true
+	case "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return textCapacities[ct.Base], true, true
+	}
+	return 0, false, false
+}
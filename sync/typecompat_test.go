@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestNormalizeType(t *testing.T) {
+	tests := []struct {
+		type1, type2 string
+		equal        bool
+	}{
+		{"int(11)", "int", true},
+		{"INT(10) UNSIGNED", "int unsigned", true},
+		{"varchar(100)", "varchar(255)", false},
+		{"decimal(10,2)", "decimal(12,4)", false},
+		{"enum('a','b')", "enum('a','b','c')", false},
+		{"enum('a','B')", "enum('a','b')", false},
+		{"datetime(3)", "datetime", false},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeType(tt.type1) == normalizeType(tt.type2); got != tt.equal {
+			t.Errorf("Expected %s and %s equal=%v, got %v", tt.type1, tt.type2, tt.equal, got)
+		}
+	}
+}
+
+func TestClassifyColumnChange(t *testing.T) {
+	tests := []struct {
+		oldType, newType string
+		expected         string
+	}{
+		{"varchar(100)", "varchar(255)", models.ChangeWidening},
+		{"varchar(255)", "varchar(100)", models.ChangeNarrowing},
+		{"varchar(255)", "text", models.ChangeWidening},
+		{"decimal(10,2)", "decimal(12,4)", models.ChangeWidening},
+		{"decimal(12,4)", "decimal(12,2)", models.ChangeNarrowing},
+		{"int", "bigint", models.ChangeWidening},
+		{"int", "int unsigned", models.ChangeNarrowing},
+		{"int", "decimal(12,0)", models.ChangeWidening},
+		{"enum('a','b')", "enum('a','b','c')", models.ChangeWidening},
+		{"enum('a','b','c')", "enum('a','c')", models.ChangeNarrowing},
+		{"datetime(6)", "datetime", models.ChangeNarrowing},
+		{"date", "datetime", models.ChangeWidening},
+		{"date", "timestamp", models.ChangeNarrowing},
+		{"datetime", "timestamp", models.ChangeNarrowing},
+		{"timestamp", "datetime", models.ChangeWidening},
+		{"timestamp", "date", models.ChangeNarrowing},
+		{"varchar(255)", "tinytext", models.ChangeNarrowing},
+		{"varchar(63)", "tinytext", models.ChangeWidening},
+		{"tinytext", "varchar(255)", models.ChangeWidening},
+		{"text", "varchar(255)", models.ChangeNarrowing},
+		{"varchar(20)", "int", models.ChangeIncompatible},
+		{"blob", "text", models.ChangeIncompatible},
+	}
+
+	for _, tt := range tests {
+		oldCol := models.Column{Type: tt.oldType, IsNullable: true}
+		newCol := models.Column{Type: tt.newType, IsNullable: true}
+		if got := classifyColumnChange(oldCol, newCol); got != tt.expected {
+			t.Errorf("Expected %s → %s to be %s, got %s", tt.oldType, tt.newType, tt.expected, got)
+		}
+	}
+
+	// 字符集决定 VARCHAR 转为 TEXT 时需要的字节数
+	latin1, utf8mb4 := "latin1", "utf8mb4"
+	if got := classifyColumnChange(models.Column{Type: "varchar(255)", Charset: &latin1}, models.Column{Type: "tinytext", Charset: &latin1}); got != models.ChangeWidening {
+		t.Errorf("Expected latin1 varchar(255) -> tinytext to be WIDENING, got %s", got)
+	}
+	if got := classifyColumnChange(models.Column{Type: "varchar(100)", Charset: &utf8mb4}, models.Column{Type: "tinytext", Charset: &utf8mb4}); got != models.ChangeNarrowing {
+		t.Errorf("Expected utf8mb4 varchar(100) -> tinytext to be NARROWING, got %s", got)
+	}
+
+	nullable := models.Column{Type: "int", IsNullable: true}
+	notNull := models.Column{Type: "int", IsNullable: false}
+	if got := classifyColumnChange(nullable, notNull); got != models.ChangeNarrowing {
+		t.Errorf("Expected NULL → NOT NULL to be %s, got %s", models.ChangeNarrowing, got)
+	}
+}
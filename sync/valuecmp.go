@@ -0,0 +1,210 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+// ValueComparator 判断同一列在源库和目标库中的两个值是否语义相等
+type ValueComparator interface {
+	Equal(a, b interface{}) bool
+}
+
+// ValueComparatorFunc 将普通函数适配为 ValueComparator
+type ValueComparatorFunc func(a, b interface{}) bool
+
+// Equal 调用函数本身
+func (f ValueComparatorFunc) Equal(a, b interface{}) bool {
+	return f(a, b)
+}
+
+// valueComparators 按列的基础类型（小写，不含长度参数，如 json、decimal）注册的比较器
+// 未注册的类型使用 defaultValueComparator
+var valueComparators = map[string]ValueComparator{
+	"json":      ValueComparatorFunc(jsonEqual),
+	"decimal":   ValueComparatorFunc(decimalEqual),
+	"numeric":   ValueComparatorFunc(decimalEqual),
+	"float":     ValueComparatorFunc(decimalEqual),
+	"double":    ValueComparatorFunc(decimalEqual),
+	"real":      ValueComparatorFunc(decimalEqual),
+	"date":      ValueComparatorFunc(temporalEqual),
+	"datetime":  ValueComparatorFunc(temporalEqual),
+	"timestamp": ValueComparatorFunc(temporalEqual),
+}
+
+// defaultValueComparator 默认比较器：[]byte 与 string 按字节比较，其余按格式化后的字符串比较
+var defaultValueComparator ValueComparator = ValueComparatorFunc(func(a, b interface{}) bool {
+	return formatValue(a) == formatValue(b)
+})
+
+// RegisterValueComparator 为指定的基础类型注册比较器，覆盖默认实现
+func RegisterValueComparator(baseType string, comparator ValueComparator) {
+	valueComparators[strings.ToLower(baseType)] = comparator
+}
+
+// valueComparatorFor 根据列的类型选择比较器
+// floatEpsilon 大于 0 时，FLOAT/DOUBLE 列按绝对误差比较
+func valueComparatorFor(col models.Column, floatEpsilon float64) ValueComparator {
+	baseType := baseColumnType(col.Type)
+
+	if floatEpsilon > 0 && (baseType == "float" || baseType == "double" || baseType == "real") {
+		return floatEpsilonComparator(floatEpsilon)
+	}
+	if comparator, exists := valueComparators[baseType]; exists {
+		return comparator
+	}
+	return defaultValueComparator
+}
+
+// baseColumnType 提取列类型的基础类型名，如 "decimal(10,2) unsigned" → "decimal"
+func baseColumnType(columnType string) string {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+	if idx := strings.IndexAny(columnType, "( "); idx >= 0 {
+		columnType = columnType[:idx]
+	}
+	return columnType
+}
+
+// nullEqual 处理 NULL 值：两边都为 NULL 时相等，仅一边为 NULL 时不等
+// 第二个返回值表示是否已得出结论
+func nullEqual(a, b interface{}) (bool, bool) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, true
+	}
+	return false, false
+}
+
+// valueString 将驱动返回的值转换为字符串
+func valueString(val interface{}) string {
+	switch v := val.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return formatValue(v)
+	}
+}
+
+// decimalEqual 按数值比较，忽略小数位数的差异（如 "1.50" 与 "1.5"）
+func decimalEqual(a, b interface{}) bool {
+	if result, done := nullEqual(a, b); done {
+		return result
+	}
+
+	ratA, okA := new(big.Rat).SetString(strings.TrimSpace(valueString(a)))
+	ratB, okB := new(big.Rat).SetString(strings.TrimSpace(valueString(b)))
+	if !okA || !okB {
+		return valueString(a) == valueString(b)
+	}
+	return ratA.Cmp(ratB) == 0
+}
+
+// floatEpsilonComparator 返回按绝对误差比较浮点数的比较器
+func floatEpsilonComparator(epsilon float64) ValueComparator {
+	return ValueComparatorFunc(func(a, b interface{}) bool {
+		if result, done := nullEqual(a, b); done {
+			return result
+		}
+
+		floatA, errA := strconv.ParseFloat(strings.TrimSpace(valueString(a)), 64)
+		floatB, errB := strconv.ParseFloat(strings.TrimSpace(valueString(b)), 64)
+		if errA != nil || errB != nil {
+			return valueString(a) == valueString(b)
+		}
+		return math.Abs(floatA-floatB) <= epsilon
+	})
+}
+
+// temporalLayouts 是 MySQL 日期时间值的文本格式
+var temporalLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02",
+}
+
+// temporalEqual 按时间点比较，time.Time 统一转换到 UTC，文本值按 UTC 解析
+func temporalEqual(a, b interface{}) bool {
+	if result, done := nullEqual(a, b); done {
+		return result
+	}
+
+	timeA, okA := toTime(a)
+	timeB, okB := toTime(b)
+	if !okA || !okB {
+		return valueString(a) == valueString(b)
+	}
+	return timeA.UTC().Equal(timeB.UTC())
+}
+
+// toTime 将驱动返回的日期时间值转换为 time.Time
+func toTime(val interface{}) (time.Time, bool) {
+	if t, ok := val.(time.Time); ok {
+		return t, true
+	}
+
+	str := strings.TrimSpace(valueString(val))
+	for _, layout := range temporalLayouts {
+		if t, err := time.ParseInLocation(layout, str, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// jsonEqual 按 JSON 语义比较，忽略对象键顺序、空白以及数字的书写形式
+func jsonEqual(a, b interface{}) bool {
+	if result, done := nullEqual(a, b); done {
+		return result
+	}
+
+	docA, errA := decodeJSON(valueString(a))
+	docB, errB := decodeJSON(valueString(b))
+	if errA != nil || errB != nil {
+		return valueString(a) == valueString(b)
+	}
+	return reflect.DeepEqual(docA, docB)
+}
+
+// decodeJSON 解析 JSON 文档，数字统一转换为规范的有理数表示
+func decodeJSON(str string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(str)))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return canonicalJSONNumbers(doc), nil
+}
+
+// canonicalJSONNumbers 递归地将 json.Number 转换为规范字符串，使 1.0 与 1 相等
+func canonicalJSONNumbers(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case json.Number:
+		if rat, ok := new(big.Rat).SetString(v.String()); ok {
+			return rat.RatString()
+		}
+		return v.String()
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = canonicalJSONNumbers(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = canonicalJSONNumbers(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
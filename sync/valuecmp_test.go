@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yuhuo/sync-db/models"
+)
+
+func TestValueComparatorFor(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	instant := time.Date(2026, 2, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		columnType string
+		a, b       interface{}
+		equal      bool
+	}{
+		{"varchar(255)", []byte("abc"), "abc", true},
+		{"varchar(255)", "abc", "abd", false},
+		{"int(11)", []byte("12"), int64(12), true},
+		{"decimal(10,2)", []byte("1.50"), "1.5", true},
+		{"decimal(10,2)", "1.50", "1.51", false},
+		{"json", `{"a": 1, "b": [1, 2]}`, []byte(`{"b":[1,2],"a":1.0}`), true},
+		{"json", `{"a": 1}`, `{"a": 2}`, false},
+		{"datetime", instant, instant.In(shanghai), true},
+		{"datetime", []byte("2026-02-07 10:00:00"), instant, true},
+		{"timestamp", instant, instant.Add(time.Second), false},
+		{"json", nil, nil, true},
+		{"json", nil, "null", false},
+	}
+
+	for _, tt := range tests {
+		comparator := valueComparatorFor(models.Column{Type: tt.columnType}, 0)
+		if got := comparator.Equal(tt.a, tt.b); got != tt.equal {
+			t.Errorf("Expected %s comparison of %v and %v to be %v, got %v", tt.columnType, tt.a, tt.b, tt.equal, got)
+		}
+	}
+}
+
+func TestFloatEpsilonComparator(t *testing.T) {
+	col := models.Column{Type: "double"}
+	a, b := 0.1, 0.2
+
+	if valueComparatorFor(col, 0).Equal(a+b, 0.3) {
+		t.Error("Expected exact comparison to treat 0.1+0.2 and 0.3 as different")
+	}
+	if !valueComparatorFor(col, 1e-9).Equal(a+b, []byte("0.3")) {
+		t.Error("Expected epsilon comparison to treat 0.1+0.2 and 0.3 as equal")
+	}
+}
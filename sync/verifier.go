@@ -3,7 +3,9 @@ package sync
 import (
 	"fmt"
 
+	"github.com/yuhuo/sync-db/config"
 	"github.com/yuhuo/sync-db/database"
+	"github.com/yuhuo/sync-db/models"
 )
 
 // Verifier 用于验证同步后的结果
@@ -12,12 +14,24 @@ type Verifier struct {
 }
 
 // NewVerifier 创建验证器
-func NewVerifier(sourceConn, targetConn *database.Connection) *Verifier {
+func NewVerifier(sourceConn, targetConn *database.Connection, options config.CompareConfig) *Verifier {
 	return &Verifier{
-		comparator: NewComparator(sourceConn, targetConn),
+		comparator: NewComparator(sourceConn, targetConn, options),
 	}
 }
 
+// WithRenames 设置显式声明的改名，与比对时使用相同的配置
+func (v *Verifier) WithRenames(renames config.RenameConfig) *Verifier {
+	v.comparator.WithRenames(renames)
+	return v
+}
+
+// WithOrphanTables 设置目标库独有的表的处理策略，与比对时使用相同的配置
+func (v *Verifier) WithOrphanTables(orphanTables config.OrphanTableConfig) *Verifier {
+	v.comparator.WithOrphanTables(orphanTables)
+	return v
+}
+
 // VerifySync 验证同步结果
 func (v *Verifier) VerifySync(syncDataTables []string) (bool, string, error) {
 	// 重新比对差异
@@ -26,6 +40,15 @@ func (v *Verifier) VerifySync(syncDataTables []string) (bool, string, error) {
 		return false, "", fmt.Errorf("failed to verify sync: %w", err)
 	}
 
+	// 仅报告的孤立表不会被同步处理，不影响验证结果
+	var orphans []models.OrphanTable
+	for _, orphan := range diff.OrphanTables {
+		if orphan.Action != models.OrphanReport {
+			orphans = append(orphans, orphan)
+		}
+	}
+	diff.OrphanTables = orphans
+
 	// 如果没有差异，则同步成功
 	if !diff.HasDifferences() {
 		return true, "Sync verification passed! No differences found.", nil
@@ -34,11 +57,21 @@ func (v *Verifier) VerifySync(syncDataTables []string) (bool, string, error) {
 	// 如果还有差异，则同步未完成
 	message := fmt.Sprintf("Sync verification failed! Still have differences:\n"+
 		"Structure differences: %d\n"+
+		"Table renames: %d\n"+
+		"Orphan tables: %d\n"+
 		"Data differences: %d\n"+
-		"View differences: %d",
+		"View differences: %d\n"+
+		"Trigger differences: %d\n"+
+		"Routine differences: %d\n"+
+		"Event differences: %d",
 		len(diff.StructureDifferences),
+		len(diff.TableRenames),
+		len(diff.OrphanTables),
 		len(diff.DataDifferences),
-		len(diff.ViewDifferences))
+		len(diff.ViewDifferences),
+		len(diff.TriggerDifferences),
+		len(diff.RoutineDifferences),
+		len(diff.EventDifferences))
 
 	return false, message, nil
 }
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/yuhuo/sync-db/models"
 )
@@ -39,8 +41,7 @@ func PrintDifferenceSummary(diff *models.SyncDifference) {
 	for tableName := range allTables {
 		structDiffs := "-"
 		if sd, exists := structMap[tableName]; exists {
-			count := len(sd.ColumnsAdded) + len(sd.ColumnsDeleted) + len(sd.ColumnsModified) + len(sd.IndexesAdded) + len(sd.IndexesDeleted)
-			if count > 0 {
+			if count := sd.ChangeCount(); count > 0 {
 				structDiffs = fmt.Sprintf("%d", count)
 			}
 		}
@@ -68,7 +69,299 @@ func PrintDifferenceSummary(diff *models.SyncDifference) {
 
 	fmt.Println()
 	fmt.Printf("Total view changes: %d\n", len(diff.ViewDifferences))
+	fmt.Printf("Total trigger changes: %d\n", len(diff.TriggerDifferences))
+	fmt.Printf("Total routine changes: %d\n", len(diff.RoutineDifferences))
+	fmt.Printf("Total event changes: %d\n", len(diff.EventDifferences))
 	fmt.Println()
+
+	// 列修改明细，标出可能丢失数据的修改
+	printColumnModifications(diff.StructureDifferences)
+
+	// 表改名和列改名
+	printTableRenames(diff.TableRenames)
+	printColumnRenames(diff.StructureDifferences)
+
+	// 仅顺序不同的列
+	printColumnOrderDifferences(diff.StructureDifferences)
+
+	// 表选项修改
+	printTableOptionChanges(diff.StructureDifferences)
+
+	// 分区修改
+	printPartitionChanges(diff.StructureDifferences)
+
+	// 字符集和排序规则的差异
+	printCharsetDrift(diff.StructureDifferences)
+
+	// 目标库现有数据不满足的 CHECK 约束
+	printCheckViolations(diff.StructureDifferences)
+
+	// 只存在于目标库的表
+	if len(diff.OrphanTables) > 0 {
+		fmt.Println("Tables only in target database:")
+		for _, orphan := range diff.OrphanTables {
+			fmt.Printf("  - %s [%s]\n", orphan.TableName, orphan.Action)
+		}
+		fmt.Println()
+	}
+
+	// 跳过数据比对的表
+	if len(diff.SkippedDataTables) > 0 {
+		fmt.Println("Tables skipped for data sync:")
+		for _, skipped := range diff.SkippedDataTables {
+			fmt.Printf("  - %s: %s\n", skipped.TableName, skipped.Reason)
+		}
+		fmt.Println()
+	}
+
+	// 跳过比对的存储过程和函数
+	if len(diff.SkippedRoutines) > 0 {
+		fmt.Println("Routines skipped:")
+		for _, skipped := range diff.SkippedRoutines {
+			fmt.Printf("  - %s %s: %s\n", strings.ToLower(skipped.RoutineType), skipped.RoutineName, skipped.Reason)
+		}
+		fmt.Println()
+	}
+}
+
+// printColumnModifications 打印列修改明细及其兼容性分类
+func printColumnModifications(structDiffs []models.StructureDifference) {
+	var lines []string
+	for _, sd := range structDiffs {
+		for _, mod := range sd.ColumnsModified {
+			marker := ""
+			if mod.Compatibility != models.ChangeWidening {
+				marker = "  ⚠ may lose data"
+			}
+			lines = append(lines, fmt.Sprintf("  %s.%s: %s → %s [%s]%s",
+				sd.TableName, mod.ColumnName, mod.OldColumn.Type, mod.NewColumn.Type, mod.Compatibility, marker))
+		}
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Println("Column modifications:")
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	fmt.Println()
+}
+
+// printTableRenames 打印声明的和推测的表改名
+func printTableRenames(renames []models.TableRename) {
+	if len(renames) == 0 {
+		return
+	}
+
+	fmt.Println("Table renames:")
+	for _, rename := range renames {
+		status := "declared"
+		if !rename.Confirmed {
+			status = "detected, needs confirmation"
+		}
+		fmt.Printf("  %s → %s [%s]\n", rename.OldName, rename.NewName, status)
+	}
+	fmt.Println()
+}
+
+// printColumnRenames 打印声明的和推测的列改名
+func printColumnRenames(structDiffs []models.StructureDifference) {
+	printed := false
+	for _, sd := range structDiffs {
+		for _, rename := range sd.ColumnsRenamed {
+			if !printed {
+				fmt.Println("Column renames:")
+				printed = true
+			}
+			status := "declared"
+			if !rename.Confirmed {
+				status = "detected, needs confirmation"
+			}
+			fmt.Printf("  %s.%s → %s [%s]\n", sd.TableName, rename.OldName, rename.NewName, status)
+		}
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// printColumnOrderDifferences 打印仅位置不同的列
+func printColumnOrderDifferences(structDiffs []models.StructureDifference) {
+	printed := false
+	for _, sd := range structDiffs {
+		if len(sd.ColumnsReordered) == 0 {
+			continue
+		}
+		if !printed {
+			fmt.Println("Columns in different order (set generate.reorder_columns to move them, may rebuild the table):")
+			printed = true
+		}
+		names := make([]string, len(sd.ColumnsReordered))
+		for i, col := range sd.ColumnsReordered {
+			names[i] = col.Name
+		}
+		fmt.Printf("  %s: %s\n", sd.TableName, strings.Join(names, ", "))
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// printTableOptionChanges 打印表选项修改，标出会重建表的修改
+func printTableOptionChanges(structDiffs []models.StructureDifference) {
+	printed := false
+	for _, sd := range structDiffs {
+		for _, change := range sd.OptionsModified {
+			if isCharsetOption(change.Option) {
+				continue
+			}
+			if !printed {
+				fmt.Println("Table option changes:")
+				printed = true
+			}
+			marker := ""
+			if change.Rebuild {
+				marker = "  ⚠ rebuilds table"
+			}
+			fmt.Printf("  %s.%s: %q → %q%s\n", sd.TableName, change.Option, change.OldValue, change.NewValue, marker)
+		}
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// printCharsetDrift 打印表的默认字符集和列的字符集、排序规则差异
+func printCharsetDrift(structDiffs []models.StructureDifference) {
+	printed := false
+	header := func() {
+		if !printed {
+			fmt.Println("Charset/collation differences:")
+			printed = true
+		}
+	}
+	for _, sd := range structDiffs {
+		for _, change := range sd.OptionsModified {
+			if !isCharsetOption(change.Option) {
+				continue
+			}
+			header()
+			marker := ""
+			if change.Rebuild {
+				marker = "  ⚠ converts all columns, rebuilds table"
+			}
+			fmt.Printf("  %s (table): %s → %s%s\n", sd.TableName, change.OldValue, change.NewValue, marker)
+		}
+		for _, change := range sd.CharsetChanges {
+			header()
+			fmt.Printf("  %s.%s: %s → %s\n", sd.TableName, change.ColumnName, change.OldCollation, change.NewCollation)
+		}
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// isCharsetOption 判断表选项是否为默认字符集的修改
+func isCharsetOption(option string) bool {
+	return option == "CONVERT TO CHARACTER SET" || option == "DEFAULT CHARACTER SET"
+}
+
+// printPartitionChanges 打印分区定义的变化，标出会删除数据的分区
+func printPartitionChanges(structDiffs []models.StructureDifference) {
+	printed := false
+	for _, sd := range structDiffs {
+		change := sd.PartitionChange
+		if change == nil {
+			continue
+		}
+		if !printed {
+			fmt.Println("Partition changes:")
+			printed = true
+		}
+
+		if change.Repartition {
+			switch {
+			case change.NewPartitioning == nil:
+				fmt.Printf("  %s: remove partitioning  ⚠ rebuilds table\n", sd.TableName)
+			case change.OldPartitioning == nil:
+				fmt.Printf("  %s: partition by %s (%s)  ⚠ rebuilds table\n", sd.TableName,
+					change.NewPartitioning.Method, change.NewPartitioning.Expression)
+			default:
+				fmt.Printf("  %s: repartition %s (%s) → %s (%s)  ⚠ rebuilds table\n", sd.TableName,
+					change.OldPartitioning.Method, change.OldPartitioning.Expression,
+					change.NewPartitioning.Method, change.NewPartitioning.Expression)
+			}
+			continue
+		}
+		for _, part := range change.PartitionsDropped {
+			fmt.Printf("  %s: drop partition %s  ⚠ deletes its rows, only generated with generate.drop_partitions\n", sd.TableName, part.Name)
+		}
+		for _, reorganize := range change.PartitionsReorganized {
+			names := make([]string, len(reorganize.Into))
+			for i, part := range reorganize.Into {
+				names[i] = part.Name
+			}
+			fmt.Printf("  %s: reorganize partition %s into %s\n", sd.TableName,
+				strings.Join(reorganize.From, ", "), strings.Join(names, ", "))
+		}
+		for _, part := range change.PartitionsAdded {
+			fmt.Printf("  %s: add partition %s\n", sd.TableName, part.Name)
+		}
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// printCheckViolations 打印目标库现有数据不满足的新增 CHECK 约束及部分违反约束的行
+func printCheckViolations(structDiffs []models.StructureDifference) {
+	printed := false
+	for _, sd := range structDiffs {
+		for _, violation := range sd.CheckViolations {
+			if !printed {
+				fmt.Println("CHECK constraints skipped (existing target rows violate them or they cannot be validated):")
+				printed = true
+			}
+			if violation.Error != "" {
+				fmt.Printf("  %s.%s: CHECK (%s), cannot be validated: %s\n",
+					sd.TableName, violation.Constraint.Name, violation.Constraint.Expression, violation.Error)
+				continue
+			}
+			fmt.Printf("  %s.%s: CHECK (%s), %d violating row(s)\n",
+				sd.TableName, violation.Constraint.Name, violation.Constraint.Expression, violation.RowCount)
+			for _, row := range violation.SampleRows {
+				fmt.Printf("    %s\n", formatRow(row))
+			}
+		}
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// formatRow 将一行数据格式化为按列名排序的 列名=值 列表
+func formatRow(row map[string]interface{}) string {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		val := row[name]
+		if b, ok := val.([]byte); ok {
+			val = string(b)
+		}
+		if val == nil {
+			val = "NULL"
+		}
+		parts[i] = fmt.Sprintf("%s=%v", name, val)
+	}
+	return strings.Join(parts, ", ")
 }
 
 // PrintSQLStatements 打印 SQL 语句列表